@@ -0,0 +1,101 @@
+package roughfa
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/berquerant/roughfa/internal/set"
+)
+
+type (
+	// charClasses maps the characters to the classes for the compiled machines.
+	// The characters that have the same transitions from all states are of the same class.
+	charClasses struct {
+		// ascii are the classes of the ascii characters.
+		ascii [utf8.RuneSelf]int32
+		// ranges are the classes of the other characters, sorted and disjoint.
+		// The characters not in the ranges are of class 0.
+		ranges []charClassRange
+		// reps are the representative characters of the classes except class 0.
+		reps []rune
+	}
+
+	charClassRange struct {
+		lo, hi rune
+		class  int32
+	}
+)
+
+// newCharClasses classifies the characters of the alphabet by the keys of their transitions.
+// Class 0 is of the characters that are not in the alphabet, and its key is other.
+// The alphabet of a nfa has no epsilon, but epsilon is an ordinary character of a dfa.
+func newCharClasses(alphabet set.RuneSet, other string, key func(c rune) string) *charClasses {
+	var (
+		cs      = alphabet.Unwrap()
+		r       = &charClasses{reps: []rune{Otherwise}}
+		classes = map[string]int32{other: 0}
+	)
+	sort.Slice(cs, func(i, j int) bool { return cs[i] < cs[j] })
+	for _, c := range cs {
+		k := key(c)
+		class, ok := classes[k]
+		if !ok {
+			class = int32(len(r.reps))
+			classes[k] = class
+			r.reps = append(r.reps, c)
+		}
+		if c < utf8.RuneSelf {
+			r.ascii[c] = class
+			continue
+		}
+		if n := len(r.ranges); n > 0 && r.ranges[n-1].hi+1 == c && r.ranges[n-1].class == class {
+			r.ranges[n-1].hi = c
+			continue
+		}
+		r.ranges = append(r.ranges, charClassRange{
+			lo:    c,
+			hi:    c,
+			class: class,
+		})
+	}
+	return r
+}
+
+// len returns the number of the classes.
+func (s *charClasses) len() int { return len(s.reps) }
+
+// classOf returns the class of the character.
+func (s *charClasses) classOf(c rune) int32 {
+	if c < utf8.RuneSelf {
+		return s.ascii[c]
+	}
+	// binary search without a closure to avoid the allocation
+	lo, hi := 0, len(s.ranges)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		switch x := s.ranges[m]; {
+		case c < x.lo:
+			hi = m
+		case c > x.hi:
+			lo = m + 1
+		default:
+			return x.class
+		}
+	}
+	return 0
+}
+
+// numberStates returns the ids of the states in the order of the first states and the states.
+func numberStates(first []string, states set.StringSet) (map[string]int32, []string) {
+	var (
+		ids   = map[string]int32{}
+		names []string
+	)
+	for _, state := range append(append([]string{}, first...), states.Unwrap()...) {
+		if _, ok := ids[state]; !ok {
+			ids[state] = int32(len(names))
+			names = append(names, state)
+		}
+	}
+	return ids, names
+}
@@ -0,0 +1,173 @@
+package roughfa
+
+import (
+	"context"
+	"io"
+
+	"github.com/berquerant/roughfa/internal/set"
+)
+
+type (
+	// Definition is an immutable machine without the current states.
+	// Definition is safe for concurrent use by multiple goroutines,
+	// and Cursor holds the current states of a run of the definition.
+	Definition interface {
+		// NewCursor creates a Cursor at the start states.
+		NewCursor() Cursor
+		// Accepts returns true if this accepts the input.
+		Accepts(input string) bool
+		// Run reads the input from the start and records the trace.
+		// Returns the trace until the error if invalid input or no transitions.
+		Run(input string) (*Trace, error)
+		// IsDFA returns true if this is a dfa.
+		IsDFA() bool
+		// ToMachine creates a new Machine at the start states.
+		ToMachine() Machine
+	}
+
+	// Cursor is a run of a Definition.
+	// Cursor is not safe for concurrent use, but the cursors of a definition are independent.
+	Cursor interface {
+		// Configuration returns the current states.
+		Configuration() []string
+		// Put inputs a character.
+		// Returns an error if invalid input or no transitions.
+		Put(x rune) error
+		// IsAccepted returns true if the current states are acceptable.
+		IsAccepted() bool
+		// Reset resets the current states to the start states.
+		Reset()
+		// Feed reads the input from r and puts the characters into this like Machine.Feed.
+		Feed(ctx context.Context, r io.RuneReader, stop FeedStop) (*FeedResult, error)
+	}
+
+	dfaDefinition struct {
+		m *dfaMachine
+		// live is the states from which an accept state is reachable.
+		live set.StringSet
+	}
+
+	dfaCursor struct {
+		d     *dfaDefinition
+		state string
+	}
+
+	nfaDefinition struct {
+		m *nfaMachine
+		// live is the states from which an accept state is reachable.
+		live set.StringSet
+	}
+
+	nfaCursor struct {
+		d      *nfaDefinition
+		states set.StringSet
+	}
+)
+
+// NewDefinition creates a Definition from the copy of the machine.
+// The current states of the machine are ignored, and the later changes of the machine do not affect the definition.
+func NewDefinition(m Machine) Definition {
+	if x, ok := m.(DFAMachine); ok {
+		d := asDFAMachine(x)
+		return &dfaDefinition{
+			m:    d,
+			live: d.coReachableStates(),
+		}
+	}
+	n := asNFAMachine(m.ToNFA())
+	return &nfaDefinition{
+		m:    n,
+		live: n.coReachableStates(),
+	}
+}
+
+// asDFAMachine creates a dfaMachine that has the same definition and current state as m.
+func asDFAMachine(m DFAMachine) *dfaMachine {
+	s := m.ToShell()
+	ts := make(map[string]map[rune]string, len(s.Transitions))
+	for fromState, x := range s.Transitions {
+		ts[fromState] = make(map[rune]string, len(x))
+		for c, toState := range x {
+			ts[fromState][c] = toState
+		}
+	}
+	return &dfaMachine{
+		states:       set.NewStringSet(s.States...),
+		chars:        set.NewRuneSet(s.Chars...),
+		startState:   s.StartState,
+		acceptStates: set.NewStringSet(s.AcceptStates...),
+		transitions:  ts,
+		currentState: s.CurrentState,
+	}
+}
+
+func (s *dfaDefinition) NewCursor() Cursor {
+	return &dfaCursor{
+		d:     s,
+		state: s.m.startState,
+	}
+}
+func (s dfaDefinition) Accepts(input string) bool        { return s.m.Accepts(input) }
+func (s dfaDefinition) Run(input string) (*Trace, error) { return s.m.Run(input) }
+func (dfaDefinition) IsDFA() bool                        { return true }
+func (s dfaDefinition) ToMachine() Machine {
+	m := asDFAMachine(s.m)
+	m.Reset()
+	return m
+}
+
+func (s dfaCursor) Configuration() []string { return []string{s.state} }
+func (s dfaCursor) IsAccepted() bool        { return s.d.m.acceptStates.In(s.state) }
+func (s *dfaCursor) Reset()                 { s.state = s.d.m.startState }
+func (s *dfaCursor) Put(x rune) error {
+	state, err := s.d.m.next(s.state, x)
+	if err != nil {
+		return err
+	}
+	s.state = state
+	return nil
+}
+func (s *dfaCursor) Feed(ctx context.Context, r io.RuneReader, stop FeedStop) (*FeedResult, error) {
+	f := &feeder{
+		put:        s.Put,
+		isAccepted: func() bool { return s.IsAccepted() },
+		isDead:     func() bool { return !s.d.live.In(s.state) },
+	}
+	return f.feed(ctx, r, stop)
+}
+
+func (s *nfaDefinition) NewCursor() Cursor {
+	return &nfaCursor{
+		d:      s,
+		states: s.m.startStates.Clone(),
+	}
+}
+func (s nfaDefinition) Accepts(input string) bool        { return s.m.Accepts(input) }
+func (s nfaDefinition) Run(input string) (*Trace, error) { return s.m.Run(input) }
+func (s nfaDefinition) IsDFA() bool                      { return s.m.IsDFA() }
+func (s nfaDefinition) ToMachine() Machine {
+	m := asNFAMachine(s.m)
+	m.Reset()
+	return m
+}
+
+func (s nfaCursor) Configuration() []string { return s.states.Unwrap() }
+func (s nfaCursor) IsAccepted() bool {
+	return s.d.m.epsilonClosure(s.states).And(s.d.m.acceptStates).Len() > 0
+}
+func (s *nfaCursor) Reset() { s.states = s.d.m.startStates.Clone() }
+func (s *nfaCursor) Put(x rune) error {
+	states, err := s.d.m.next(s.states, x)
+	if states != nil {
+		s.states = states
+	}
+	return err
+}
+func (s *nfaCursor) Feed(ctx context.Context, r io.RuneReader, stop FeedStop) (*FeedResult, error) {
+	f := &feeder{
+		put:        s.Put,
+		isAccepted: func() bool { return s.IsAccepted() },
+		isDead:     func() bool { return s.d.m.epsilonClosure(s.states).And(s.d.live).Len() == 0 },
+	}
+	return f.feed(ctx, r, stop)
+}
@@ -0,0 +1,88 @@
+package roughfa_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefinition(t *testing.T) {
+	n, err := roughfa.CompileRegexp("(a|b)*a(a|b)|c?")
+	if !assert.Nil(t, err) {
+		return
+	}
+	p, err := n.ApplyEpsilonExpansion().ApplyPowersetConstruction()
+	if !assert.Nil(t, err) {
+		return
+	}
+	d, err := p.ToDFA()
+	if !assert.Nil(t, err) {
+		return
+	}
+	words := allWords("abc", 5)
+
+	for _, tc := range []*struct {
+		title string
+		m     roughfa.Machine
+	}{
+		{
+			title: "dfa",
+			m:     d,
+		},
+		{
+			title: "nfa",
+			m:     n,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			def := roughfa.NewDefinition(tc.m)
+			assert.Equal(t, tc.m.IsDFA(), def.IsDFA())
+			// the changes of the machine do not affect the definition
+			assert.Nil(t, tc.m.Put('a'))
+
+			var (
+				wg  sync.WaitGroup
+				got = make([][]bool, 8)
+			)
+			for i := range got {
+				i := i
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					c := def.NewCursor()
+					got[i] = make([]bool, len(words))
+					for j, w := range words {
+						c.Reset()
+						accepted := true
+						for _, x := range w {
+							if err := c.Put(x); err != nil {
+								accepted = false
+								break
+							}
+						}
+						got[i][j] = accepted && c.IsAccepted() && def.Accepts(w)
+					}
+				}()
+			}
+			wg.Wait()
+
+			tc.m.Reset()
+			for i := range got {
+				for j, w := range words {
+					assert.Equal(t, tc.m.Accepts(w), got[i][j], "%d %q", i, w)
+				}
+			}
+
+			c := def.NewCursor()
+			assert.Nil(t, c.Put('a'))
+			assert.ElementsMatch(t, tc.m.Configuration(), def.NewCursor().Configuration())
+			x := def.ToMachine()
+			assert.Nil(t, x.Put('a'))
+			assert.ElementsMatch(t, c.Configuration(), x.Configuration())
+			assert.NotNil(t, c.Put('d'))
+		})
+	}
+}
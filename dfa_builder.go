@@ -20,6 +20,7 @@ type (
 		// Must be a subset of States.
 		AcceptStates(acceptStates []string) DFAMachineBuilder
 		// Transitions configures the transition function of the machine.
+		// Otherwise is for the otherwise transitions.
 		// Required.
 		Transitions(transitions map[string]map[rune]string) DFAMachineBuilder
 		// Build creates a new DFAMachine.
@@ -82,6 +83,7 @@ func (s dfaMachineBuilder) Build() (DFAMachine, error) {
 		}
 	}
 	chars := set.NewRuneSet(s.chars...)
+	tChars.Del(Otherwise)
 	if !states.In(tStates.Unwrap()...) || chars.Len() > 0 && !chars.In(tChars.Unwrap()...) {
 		return nil, ErrInvalidTransitions
 	}
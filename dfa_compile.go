@@ -0,0 +1,117 @@
+package roughfa
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+type (
+	// CompiledDFA is a dfa compiled into a dense transition table for matching.
+	// The states are numbered, and the characters that have the same transitions from all states
+	// are compressed into a class.
+	// CompiledDFA is immutable and safe for concurrent use by multiple goroutines.
+	CompiledDFA interface {
+		// Match returns true if the dfa accepts the utf-8 encoded input.
+		// An invalid utf-8 sequence is read as utf8.RuneError like the range over the string.
+		// Match does not allocate.
+		Match(input []byte) bool
+		// MatchString is Match for the string.
+		MatchString(input string) bool
+		// NumStates returns the number of the states.
+		NumStates() int
+		// NumClasses returns the number of the classes of the characters.
+		NumClasses() int
+	}
+
+	compiledDFA struct {
+		classes *charClasses
+		// table is the next states by the state * the number of the classes + the class.
+		// The negative state means no transitions or invalid input.
+		// The start state is 0.
+		table  []int32
+		accept []bool
+	}
+)
+
+func (s dfaMachine) Compile() CompiledDFA {
+	ids, names := numberStates([]string{s.startState}, s.states)
+	column := func(c rune) []int32 {
+		xs := make([]int32, len(names))
+		for i, state := range names {
+			xs[i] = -1
+			if next, ok := s.move(state, c); ok {
+				xs[i] = ids[next]
+			}
+		}
+		return xs
+	}
+	// the characters that appear in no transitions are invalid if the chars are not universe
+	other := make([]int32, len(names))
+	for i := range other {
+		other[i] = -1
+	}
+	if s.chars.Len() == 0 {
+		other = column(Otherwise)
+	}
+	var (
+		classes = newCharClasses(s.alphabet(), fmt.Sprint(other), func(c rune) string { return fmt.Sprint(column(c)) })
+		n       = classes.len()
+		r       = &compiledDFA{
+			classes: classes,
+			table:   make([]int32, len(names)*n),
+			accept:  make([]bool, len(names)),
+		}
+	)
+	for class, c := range classes.reps {
+		xs := other
+		if class > 0 {
+			xs = column(c)
+		}
+		for i := range names {
+			r.table[i*n+class] = xs[i]
+		}
+	}
+	for i, state := range names {
+		r.accept[i] = s.acceptStates.In(state)
+	}
+	return r
+}
+
+func (s compiledDFA) NumStates() int  { return len(s.accept) }
+func (s compiledDFA) NumClasses() int { return s.classes.len() }
+
+func (s *compiledDFA) Match(input []byte) bool {
+	var (
+		state int32
+		n     = s.classes.len()
+	)
+	for i := 0; i < len(input); {
+		c, size := rune(input[i]), 1
+		if c >= utf8.RuneSelf {
+			c, size = utf8.DecodeRune(input[i:])
+		}
+		if state = s.table[int(state)*n+int(s.classes.classOf(c))]; state < 0 {
+			return false
+		}
+		i += size
+	}
+	return s.accept[state]
+}
+
+func (s *compiledDFA) MatchString(input string) bool {
+	var (
+		state int32
+		n     = s.classes.len()
+	)
+	for i := 0; i < len(input); {
+		c, size := rune(input[i]), 1
+		if c >= utf8.RuneSelf {
+			c, size = utf8.DecodeRuneInString(input[i:])
+		}
+		if state = s.table[int(state)*n+int(s.classes.classOf(c))]; state < 0 {
+			return false
+		}
+		i += size
+	}
+	return s.accept[state]
+}
@@ -0,0 +1,153 @@
+package roughfa_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRegexpDFA compiles the pattern into a minimal dfa.
+func newRegexpDFA(pattern string) (roughfa.DFAMachine, error) {
+	n, err := roughfa.CompileRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if n, err = n.ApplyEpsilonExpansion().ApplyPowersetConstruction(); err != nil {
+		return nil, err
+	}
+	d, err := n.ToDFA()
+	if err != nil {
+		return nil, err
+	}
+	m, _ := d.Minimize()
+	return m, nil
+}
+
+func TestCompiledDFA(t *testing.T) {
+	comment, err := newCommentMachine(nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	declared, err := newCommentMachine([]rune{'/', '\n', 'x', roughfa.Otherwise})
+	if !assert.Nil(t, err) {
+		return
+	}
+	abb, err := newRegexpDFA("(a|b)*abb")
+	if !assert.Nil(t, err) {
+		return
+	}
+	kana, err := newRegexpDFA("(あ|い|う|x)*え")
+	if !assert.Nil(t, err) {
+		return
+	}
+	// epsilon is an ordinary character of a dfa
+	epsilon, err := roughfa.NewDFAMachineBuilder().
+		States([]string{"0", "1"}).
+		StartState("0").
+		AcceptStates([]string{"1"}).
+		Transitions(map[string]map[rune]string{
+			"0": {
+				'a':             "0",
+				roughfa.Epsilon: "1",
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	for _, tc := range []*struct {
+		title      string
+		m          roughfa.DFAMachine
+		chars      string
+		numClasses int
+	}{
+		{
+			title:      "otherwise",
+			m:          comment,
+			chars:      "/\nxy",
+			numClasses: 3,
+		},
+		{
+			title:      "otherwise with chars",
+			m:          declared,
+			chars:      "/\nxy",
+			numClasses: 4,
+		},
+		{
+			title:      "regexp",
+			m:          abb,
+			chars:      "abc",
+			numClasses: 3,
+		},
+		{
+			title:      "non-ascii",
+			m:          kana,
+			chars:      "あいうえxお",
+			numClasses: 3,
+		},
+		{
+			title:      "epsilon",
+			m:          epsilon,
+			chars:      "aεb",
+			numClasses: 3,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			c := tc.m.Compile()
+			assert.Equal(t, tc.numClasses, c.NumClasses())
+			for _, w := range allWords(tc.chars, 4) {
+				want := tc.m.Accepts(w)
+				assert.Equal(t, want, c.MatchString(w), "%q", w)
+				assert.Equal(t, want, c.Match([]byte(w)), "bytes %q", w)
+			}
+			assert.Equal(t, tc.m.Accepts("\xff"), c.MatchString("\xff"))
+		})
+	}
+
+	t.Run("no allocation", func(t *testing.T) {
+		var (
+			c     = kana.Compile()
+			input = []byte(strings.Repeat("あいx", 100) + "え")
+		)
+		assert.Equal(t, 0.0, testing.AllocsPerRun(10, func() {
+			assert.True(t, c.Match(input))
+		}))
+	})
+}
+
+func BenchmarkDFAMatch(b *testing.B) {
+	m, err := newRegexpDFA("(a|b|あ)*a(a|b|あ)(a|b|あ)")
+	if err != nil {
+		b.Fatal(err)
+	}
+	var (
+		input = strings.Repeat("abあb", 256) + "aab"
+		c     = m.Compile()
+	)
+	b.Run("map", func(b *testing.B) {
+		b.SetBytes(int64(len(input)))
+		for i := 0; i < b.N; i++ {
+			m.Reset()
+			for _, x := range input {
+				if err := m.Put(x); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if !m.IsAccepted() {
+				b.Fatal("not accepted")
+			}
+		}
+	})
+	b.Run("compiled", func(b *testing.B) {
+		b.SetBytes(int64(len(input)))
+		for i := 0; i < b.N; i++ {
+			if !c.MatchString(input) {
+				b.Fatal("not accepted")
+			}
+		}
+	})
+}
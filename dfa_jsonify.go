@@ -1,52 +1,136 @@
 package roughfa
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
 
 type (
 	// DFAMachineShell is a serializable form of DFAMachine.
+	// See JSONShellVersion for the json format.
 	DFAMachineShell struct {
-		States         []string                     `json:"states"`
-		Chars          []rune                       `json:"-"`
-		RawChars       []string                     `json:"chars,omitempty"`
-		StartState     string                       `json:"start_state"`
-		AcceptStates   []string                     `json:"accept_states"`
-		Transitions    map[string]map[rune]string   `json:"-"`
+		States []string `json:"states"`
+		Chars  []rune   `json:"-"`
+		// Deprecated: RawChars is the chars of the json in the version 1, use ToJSON and Chars instead.
+		RawChars     []string                   `json:"chars,omitempty"`
+		StartState   string                     `json:"start_state"`
+		AcceptStates []string                   `json:"accept_states"`
+		Transitions  map[string]map[rune]string `json:"-"`
+		// Deprecated: RawTransitions is the transitions of the json in the version 1, use ToJSON and Transitions instead.
 		RawTransitions map[string]map[string]string `json:"transitions"`
 		CurrentState   string                       `json:"current_state,omitempty"`
 	}
+
+	dfaMachineShellJSON struct {
+		Version      int                           `json:"version"`
+		States       []string                      `json:"states"`
+		Chars        []string                      `json:"chars,omitempty"`
+		StartState   string                        `json:"start_state"`
+		AcceptStates []string                      `json:"accept_states"`
+		Transitions  map[string]*dfaStateShellJSON `json:"transitions"`
+		CurrentState string                        `json:"current_state,omitempty"`
+	}
+
+	dfaStateShellJSON struct {
+		On        map[string]string `json:"on,omitempty"`
+		Otherwise string            `json:"otherwise,omitempty"`
+	}
 )
 
 func (s DFAMachineShell) ToJSON() ([]byte, error) {
-	rcs := make([]string, len(s.Chars))
-	for i, x := range s.Chars {
-		rcs[i] = string(x)
-	}
-	rts := make(map[string]map[string]string, len(s.Transitions))
+	ts := make(map[string]*dfaStateShellJSON, len(s.Transitions))
 	for k, x := range s.Transitions {
-		rts[k] = make(map[string]string, len(x))
-		for kx, kv := range x {
-			rts[k][string(kx)] = kv
+		var (
+			state = &dfaStateShellJSON{}
+			cs    []rune
+		)
+		for c, toState := range x {
+			if c == Otherwise {
+				state.Otherwise = toState
+				continue
+			}
+			cs = append(cs, c)
 		}
+		if len(cs) > 0 {
+			state.On = map[string]string{}
+		}
+		for toState, labels := range groupJSONLabels(cs, func(c rune) string { return x[c] }) {
+			for _, label := range labels {
+				state.On[label] = toState
+			}
+		}
+		ts[k] = state
 	}
-	return json.Marshal(DFAMachineShell{
-		States:         s.States,
-		RawChars:       rcs,
-		StartState:     s.StartState,
-		AcceptStates:   s.AcceptStates,
-		RawTransitions: rts,
-		CurrentState:   s.CurrentState,
+	return json.Marshal(dfaMachineShellJSON{
+		Version:      JSONShellVersion,
+		States:       s.States,
+		Chars:        formatJSONLabels(s.Chars),
+		StartState:   s.StartState,
+		AcceptStates: s.AcceptStates,
+		Transitions:  ts,
+		CurrentState: s.CurrentState,
 	})
 }
 
+// NewDFAMachineShellFromJSON creates a DFAMachineShell from the json of any version.
+// Returns an error if the labels of a state overlap and the destinations differ.
 func NewDFAMachineShellFromJSON(b []byte) (*DFAMachineShell, error) {
-	var s DFAMachineShell
+	v, err := jsonShellVersionOf(b)
+	if err != nil {
+		return nil, err
+	}
+	if v == 1 {
+		return newDFAMachineShellFromJSONV1(b)
+	}
+	var s dfaMachineShellJSON
 	if err := json.Unmarshal(b, &s); err != nil {
 		return nil, err
 	}
+	cs, err := parseJSONChars(s.Chars)
+	if err != nil {
+		return nil, err
+	}
+	ts := make(map[string]map[rune]string, len(s.Transitions))
+	for k, x := range s.Transitions {
+		ts[k] = map[rune]string{}
+		if x == nil {
+			continue
+		}
+		for label, toState := range x.On {
+			r, err := parseJSONLabel(label, Otherwise)
+			if err != nil {
+				return nil, err
+			}
+			for c := r.Lo; c <= r.Hi; c++ {
+				if y, ok := ts[k][c]; ok && y != toState {
+					return nil, fmt.Errorf("%w: conflicting label %q", ErrCannotUnmarshalMachine, label)
+				}
+				ts[k][c] = toState
+			}
+		}
+		if x.Otherwise != "" {
+			ts[k][Otherwise] = x.Otherwise
+		}
+	}
+	return &DFAMachineShell{
+		States:       s.States,
+		Chars:        cs,
+		StartState:   s.StartState,
+		AcceptStates: s.AcceptStates,
+		Transitions:  ts,
+		CurrentState: s.CurrentState,
+	}, nil
+}
 
+func newDFAMachineShellFromJSONV1(b []byte) (*DFAMachineShell, error) {
+	var s DFAMachineShell
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
 	cs := make([]rune, len(s.RawChars))
 	for i, x := range s.RawChars {
-		if len(x) != 1 {
+		if utf8.RuneCountInString(x) != 1 {
 			return nil, ErrCannotUnmarshalMachine
 		}
 		cs[i] = []rune(x)[0]
@@ -55,7 +139,7 @@ func NewDFAMachineShellFromJSON(b []byte) (*DFAMachineShell, error) {
 	for k, x := range s.RawTransitions {
 		ts[k] = make(map[rune]string, len(x))
 		for kx, kv := range x {
-			if len(kx) != 1 {
+			if utf8.RuneCountInString(kx) != 1 {
 				return nil, ErrCannotUnmarshalMachine
 			}
 			ts[k][[]rune(kx)[0]] = kv
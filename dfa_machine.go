@@ -1,29 +1,40 @@
 package roughfa
 
 import (
+	"math/big"
+
 	"github.com/berquerant/roughfa/internal/dot"
 	"github.com/berquerant/roughfa/internal/set"
 )
 
 type (
 	// DFAMachine is a runner of the deterministic finite automaton.
+	// See ToGenericDFA for the machine over the symbols other than runes.
 	DFAMachine interface {
+		Machine
 		// State returns the current state.
 		State() string
 		// SetState sets the state as the current state.
 		// Returns an error if the state is not in the states.
 		SetState(state string) error
-		// Put inputs a character.
-		// Returns an error if invalid input or no transitions.
-		Put(x rune) error
-		// IsAccepted returns true if the current state is in the accept states.
-		IsAccepted() bool
-		// Reset resets the current state to the start state.
-		Reset()
-		// ToDot generates Dot.
-		ToDot() (dot.Dot, error)
 		// ToShell generates DFAMachineShell.
 		ToShell() *DFAMachineShell
+		// Minimize creates the minimal dfa that accepts the same language.
+		// Returns the minimal dfa and the map from the states of this to the states of the minimal dfa.
+		// The states that are unreachable or cannot reach an accept state are not in the map,
+		// except the start state.
+		Minimize() (DFAMachine, map[string]string)
+		// Complete creates a DFAMachine that has the transitions for all chars from all states,
+		// by adding a sink state that is named sink or sink followed by primes.
+		// If the chars are universe, the chars of the transitions are used as the chars.
+		// The sink state is not added if this has all transitions already.
+		Complete() DFAMachine
+		// Trim creates a DFAMachine without the states that are unreachable from the start state
+		// or cannot reach an accept state, except the start state.
+		// The current state is reset to the start state if it is removed.
+		Trim() DFAMachine
+		// Compile creates a CompiledDFA that accepts the same words as this.
+		Compile() CompiledDFA
 	}
 	dfaMachine struct {
 		states       set.StringSet
@@ -53,6 +64,12 @@ func (s dfaMachine) ToDot() (dot.Dot, error) {
 		Transitions(s.transitions).
 		Build()
 }
+func (s dfaMachine) IsEmpty() (bool, string)           { return FromDFA(&s).IsEmpty() }
+func (s dfaMachine) IsUniversal() (bool, string)       { return FromDFA(&s).IsUniversal() }
+func (s dfaMachine) IsFinite() (bool, *PumpingWitness) { return FromDFA(&s).IsFinite() }
+func (s dfaMachine) LanguageSize() (*big.Int, error)   { return FromDFA(&s).LanguageSize() }
+func (s dfaMachine) CountWords(n int) *big.Int         { return FromDFA(&s).CountWords(n) }
+func (s dfaMachine) CountWordsUpTo(n int) *big.Int     { return FromDFA(&s).CountWordsUpTo(n) }
 func (s *dfaMachine) SetState(state string) error {
 	if !s.states.In(state) {
 		return ErrInvalidState
@@ -64,17 +81,22 @@ func (s *dfaMachine) Reset()          { s.currentState = s.startState }
 func (s dfaMachine) IsAccepted() bool { return s.acceptStates.In(s.currentState) }
 func (s dfaMachine) State() string    { return s.currentState }
 func (s *dfaMachine) Put(x rune) error {
-	if s.chars.Len() > 0 && !s.chars.In(x) {
-		return ErrInvalidInputChar
+	nextState, err := s.next(s.currentState, x)
+	if err != nil {
+		return err
 	}
-	t, ok := s.transitions[s.currentState]
-	if !ok {
-		return ErrOutOfTransition
+	s.currentState = nextState
+	return nil
+}
+
+// next returns the state after reading x from the state.
+func (s dfaMachine) next(state string, x rune) (string, error) {
+	if s.chars.Len() > 0 && !s.chars.In(x) {
+		return "", ErrInvalidInputChar
 	}
-	nextState, ok := t[x]
+	nextState, ok := s.move(state, x)
 	if !ok {
-		return ErrOutOfTransition
+		return "", ErrOutOfTransition
 	}
-	s.currentState = nextState
-	return nil
+	return nextState, nil
 }
@@ -8,7 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func ExampleDFAMachineRun() {
+func ExampleDFAMachine_run() {
 	m, err := roughfa.NewDFAMachineBuilder().
 		States([]string{"even", "odd"}).
 		StartState("even").
@@ -0,0 +1,289 @@
+package roughfa
+
+import (
+	"github.com/berquerant/roughfa/internal/set"
+)
+
+type (
+	// hopcroftPartition is a partition of the states of a complete dfa.
+	// The states are represented by the indices.
+	hopcroftPartition struct {
+		// block[q] is the index of the block that contains q.
+		block []int
+		// blocks[i] is the states of the block i.
+		blocks [][]int
+		// pos[q] is the index of q in the block.
+		pos []int
+	}
+)
+
+// add adds a block of the states xs.
+func (s *hopcroftPartition) add(xs []int) {
+	for i, x := range xs {
+		s.block[x] = len(s.blocks)
+		s.pos[x] = i
+	}
+	s.blocks = append(s.blocks, xs)
+}
+
+// split splits the blocks by the states xs in O(len(xs)),
+// by moving the states to the end of the block and cutting off them into a new block.
+// Returns the pairs of the index of the split block and the index of the new block.
+func (s *hopcroftPartition) split(xs []int) [][2]int {
+	var (
+		marked = map[int][]int{}
+		order  []int
+	)
+	for _, x := range xs {
+		b := s.block[x]
+		if _, ok := marked[b]; !ok {
+			order = append(order, b)
+		}
+		marked[b] = append(marked[b], x)
+	}
+	var result [][2]int
+	for _, b := range order {
+		var (
+			in    = marked[b]
+			block = s.blocks[b]
+			end   = len(block)
+		)
+		if len(in) == end {
+			continue
+		}
+		// the states after end are the moved states
+		for _, x := range in {
+			end--
+			i, y := s.pos[x], block[end]
+			block[i], block[end] = y, x
+			s.pos[y], s.pos[x] = i, end
+		}
+		n := len(s.blocks)
+		s.blocks[b] = block[:end:end]
+		s.add(block[end:])
+		result = append(result, [2]int{b, n})
+	}
+	return result
+}
+
+// Minimize minimizes the dfa by the partition refinement of Hopcroft in O(n log n).
+// A missing transition goes to the implicit sink state, and the states equivalent to the sink are excluded.
+// A state of the minimal dfa is named the least name of the equivalent states.
+// The current state of the minimal dfa is the start state.
+func (s dfaMachine) Minimize() (DFAMachine, map[string]string) {
+	s = *s.expandOtherwise()
+	chars := s.alphabet()
+	r := minimizeDFA(sortedStrings(s.reachableStates()), sortedRunes(chars), s.startState,
+		func(q string, c rune) (string, bool) {
+			y, ok := s.transitions[q][c]
+			return y, ok
+		},
+		func(q string) bool { return s.acceptStates.In(q) },
+	)
+	chars.Del(Otherwise)
+	m := &dfaMachine{
+		states:       set.NewStringSet(r.states...),
+		chars:        s.chars.Clone(),
+		startState:   r.startState,
+		acceptStates: set.NewStringSet(r.acceptStates...),
+		transitions:  r.transitions,
+		currentState: r.startState,
+	}
+	return m.blockOtherwise(chars), r.mapping
+}
+
+type (
+	// minimalDFA is a minimal dfa assembled by minimizeDFA.
+	minimalDFA[S, Q comparable] struct {
+		states       []Q
+		startState   Q
+		acceptStates []Q
+		transitions  map[Q]map[S]Q
+		// mapping is the map from the states of the original dfa to the states of the minimal dfa.
+		mapping map[Q]Q
+	}
+)
+
+// minimizeDFA minimizes the dfa whose states are names and symbols are alphabet by hopcroftRefine,
+// and assembles the minimal dfa.
+// The names must be the states reachable from the start state.
+// A state of the minimal dfa is named the first of the equivalent states in the order of names,
+// and the states equivalent to the sink are excluded except the start state.
+func minimizeDFA[S, Q comparable](names []Q, alphabet []S, start Q, move func(q Q, c S) (Q, bool), accept func(q Q) bool) *minimalDFA[S, Q] {
+	var (
+		index = make(map[Q]int, len(names))
+		sink  = len(names)
+	)
+	for i, x := range names {
+		index[x] = i
+	}
+	p := hopcroftRefine(len(names), len(alphabet), func(q, i int) int {
+		if y, ok := move(names[q], alphabet[i]); ok {
+			return index[y]
+		}
+		return sink
+	}, func(q int) bool { return accept(names[q]) })
+
+	var (
+		sinkBlock  = p.block[sink]
+		startBlock = p.block[index[start]]
+		// firsts[b] is the first state of the block b
+		firsts = map[int]int{}
+		r      = &minimalDFA[S, Q]{
+			transitions: map[Q]map[S]Q{},
+			mapping:     map[Q]Q{},
+		}
+	)
+	for b, xs := range p.blocks {
+		if b == sinkBlock && b != startBlock {
+			continue
+		}
+		first := -1
+		for _, x := range xs {
+			if x != sink && (first < 0 || x < first) {
+				first = x
+			}
+		}
+		firsts[b] = first
+		for _, x := range xs {
+			if x != sink {
+				r.mapping[names[x]] = names[first]
+			}
+		}
+	}
+	for i, x := range names {
+		if first, ok := firsts[p.block[i]]; !ok || first != i {
+			continue
+		}
+		r.states = append(r.states, x)
+		if accept(x) {
+			r.acceptStates = append(r.acceptStates, x)
+		}
+		for _, c := range alphabet {
+			y, ok := move(x, c)
+			if !ok {
+				continue
+			}
+			to := p.block[index[y]]
+			if to == sinkBlock {
+				continue
+			}
+			if _, ok := r.transitions[x]; !ok {
+				r.transitions[x] = map[S]Q{}
+			}
+			r.transitions[x][c] = names[firsts[to]]
+		}
+	}
+	r.startState = names[firsts[startBlock]]
+	return r
+}
+
+// hopcroftRefine computes the partition of the states of a dfa into the equivalent states
+// by the partition refinement of Hopcroft.
+// The states are represented by 0 to n-1 and n is the implicit sink state, the symbols by 0 to k-1.
+// next(q, i) returns the destination of q by the symbol i, or n if no transitions.
+func hopcroftRefine(n, k int, next func(q, i int) int, accept func(q int) bool) *hopcroftPartition {
+	// inverse[i][q] is the states that move to q by i
+	inverse := make([][][]int, k)
+	for i := 0; i < k; i++ {
+		inverse[i] = make([][]int, n+1)
+		for x := 0; x < n; x++ {
+			to := next(x, i)
+			inverse[i][to] = append(inverse[i][to], x)
+		}
+		inverse[i][n] = append(inverse[i][n], n)
+	}
+
+	p := &hopcroftPartition{
+		block: make([]int, n+1),
+		pos:   make([]int, n+1),
+	}
+	{
+		var accepts, others []int
+		for i := 0; i < n; i++ {
+			if accept(i) {
+				accepts = append(accepts, i)
+				continue
+			}
+			others = append(others, i)
+		}
+		others = append(others, n)
+		for _, b := range [][]int{others, accepts} {
+			if len(b) > 0 {
+				p.add(b)
+			}
+		}
+	}
+	var (
+		waiting   = map[int]bool{}
+		waitQueue []int
+		wait      = func(b int) {
+			if !waiting[b] {
+				waiting[b] = true
+				waitQueue = append(waitQueue, b)
+			}
+		}
+	)
+	// it is enough to wait for the smaller one
+	if len(p.blocks) == 2 && len(p.blocks[1]) < len(p.blocks[0]) {
+		wait(1)
+	} else {
+		wait(0)
+	}
+	for len(waitQueue) > 0 {
+		a := waitQueue[0]
+		waitQueue = waitQueue[1:]
+		waiting[a] = false
+		// the block may be split while splitting by itself
+		splitter := append([]int{}, p.blocks[a]...)
+		for i := 0; i < k; i++ {
+			var xs []int
+			for _, q := range splitter {
+				xs = append(xs, inverse[i][q]...)
+			}
+			for _, x := range p.split(xs) {
+				old, created := x[0], x[1]
+				if waiting[old] || len(p.blocks[created]) <= len(p.blocks[old]) {
+					wait(created)
+					continue
+				}
+				wait(old)
+			}
+		}
+	}
+
+	return p
+}
+
+// reachableStates returns the states reachable from the start state.
+func (s dfaMachine) reachableStates() set.StringSet {
+	var (
+		states = set.NewStringSet(s.startState)
+		q      = []string{s.startState}
+	)
+	for len(q) > 0 {
+		x := q[0]
+		q = q[1:]
+		for _, y := range s.transitions[x] {
+			if !states.In(y) {
+				states.Add(y)
+				q = append(q, y)
+			}
+		}
+	}
+	return states
+}
+
+// alphabet returns the chars, or the chars of the transitions if the chars are universe.
+func (s dfaMachine) alphabet() set.RuneSet {
+	if s.chars.Len() > 0 {
+		return s.chars.Clone()
+	}
+	cs := set.NewRuneSet()
+	for _, x := range s.transitions {
+		for c := range x {
+			cs.Add(c)
+		}
+	}
+	return cs
+}
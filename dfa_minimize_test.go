@@ -0,0 +1,158 @@
+package roughfa_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+type dfaMinimizeTestcase struct {
+	name         string
+	states       []string
+	startState   string
+	acceptStates []string
+	transitions  map[string]map[rune]string
+	wantStates   []string
+	wantMapping  map[string]string
+}
+
+func (s dfaMinimizeTestcase) test(t *testing.T) {
+	m, err := roughfa.NewDFAMachineBuilder().
+		States(s.states).
+		StartState(s.startState).
+		AcceptStates(s.acceptStates).
+		Transitions(s.transitions).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	got, mapping := m.Minimize()
+	assert.ElementsMatch(t, s.wantStates, got.ToShell().States, "states")
+	assert.Equal(t, s.wantMapping, mapping, "mapping")
+	assert.Equal(t, mapping[s.startState], got.State(), "current state")
+	ok, w := roughfa.EquivalentDFA(m, got)
+	assert.True(t, ok, "equivalent, distinguished by %q", w)
+}
+
+func TestDFAMinimize(t *testing.T) {
+	for _, tc := range []*dfaMinimizeTestcase{
+		{
+			name:         "empty",
+			states:       []string{"q0", "q1"},
+			startState:   "q0",
+			acceptStates: []string{},
+			transitions: map[string]map[rune]string{
+				"q0": {'a': "q1"},
+				"q1": {'a': "q0"},
+			},
+			wantStates: []string{"q0"},
+			wantMapping: map[string]string{
+				"q0": "q0",
+				"q1": "q0",
+			},
+		},
+		{
+			name:         "minimal",
+			states:       []string{"even", "odd"},
+			startState:   "even",
+			acceptStates: []string{"odd"},
+			transitions: map[string]map[rune]string{
+				"even": {'0': "even", '1': "odd"},
+				"odd":  {'0': "odd", '1': "even"},
+			},
+			wantStates: []string{"even", "odd"},
+			wantMapping: map[string]string{
+				"even": "even",
+				"odd":  "odd",
+			},
+		},
+		{
+			name:         "merge equivalent states",
+			states:       []string{"a", "b", "c", "d", "e"},
+			startState:   "a",
+			acceptStates: []string{"c", "e"},
+			transitions: map[string]map[rune]string{
+				"a": {'0': "b", '1': "d"},
+				"b": {'0': "c", '1': "b"},
+				"c": {'0': "c", '1': "c"},
+				"d": {'0': "e", '1': "d"},
+				"e": {'0': "e", '1': "c"},
+			},
+			wantStates: []string{"a", "b", "c"},
+			wantMapping: map[string]string{
+				"a": "a",
+				"b": "b",
+				"c": "c",
+				"d": "b",
+				"e": "c",
+			},
+		},
+		{
+			name:         "drop unreachable and dead states",
+			states:       []string{"s", "t", "u", "dead", "island"},
+			startState:   "s",
+			acceptStates: []string{"t", "island"},
+			transitions: map[string]map[rune]string{
+				"s":      {'a': "t", 'b': "dead"},
+				"t":      {'a': "u", 'b': "dead"},
+				"u":      {'a': "t"},
+				"dead":   {'a': "dead", 'b': "dead"},
+				"island": {'a': "t"},
+			},
+			wantStates: []string{"s", "t"},
+			wantMapping: map[string]string{
+				"s": "s",
+				"t": "t",
+				"u": "s",
+			},
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestDFAMinimizeRegexp(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		chars   string
+		want    int
+	}{
+		{pattern: "(a|b)*abb", chars: "ab", want: 4},
+		{pattern: "(a|b)*a(a|b)(a|b)", chars: "ab", want: 8},
+		{pattern: "a*|b*", chars: "ab", want: 3},
+		{pattern: "(aa|aaa)*", chars: "a", want: 3},
+		{pattern: "abc|abd|xbc|xbd", chars: "abcdx", want: 4},
+	} {
+		tc := tc
+		t.Run(tc.pattern, func(t *testing.T) {
+			n, err := roughfa.CompileRegexp(tc.pattern)
+			if !assert.Nil(t, err) {
+				return
+			}
+			n, err = n.ApplyEpsilonExpansion().ApplyPowersetConstruction()
+			if !assert.Nil(t, err) {
+				return
+			}
+			d, err := n.ToDFA()
+			if !assert.Nil(t, err) {
+				return
+			}
+			m, _ := d.Minimize()
+			assert.Equal(t, tc.want, len(m.ToShell().States), "states")
+			r := regexp.MustCompile(`^(?:` + tc.pattern + `)$`)
+			for _, w := range allWords(tc.chars, 6) {
+				m.Reset()
+				accepted := true
+				for _, c := range w {
+					if err := m.Put(c); err != nil {
+						accepted = false
+						break
+					}
+				}
+				assert.Equal(t, r.MatchString(w), accepted && m.IsAccepted(), "word %q", w)
+			}
+		})
+	}
+}
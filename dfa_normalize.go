@@ -0,0 +1,129 @@
+package roughfa
+
+import "github.com/berquerant/roughfa/internal/set"
+
+func (s dfaMachine) Complete() DFAMachine {
+	s = *s.expandOtherwise()
+	var (
+		alphabet    = s.alphabet()
+		sink        = uniqueName(s.states, sinkState)
+		states      = s.states.Clone()
+		transitions = s.cloneTransitions()
+		connect     = func(from string, c rune, to string) {
+			if _, ok := transitions[from]; !ok {
+				transitions[from] = map[rune]string{}
+			}
+			transitions[from][c] = to
+		}
+	)
+	for _, state := range s.states.Unwrap() {
+		for _, c := range alphabet.Unwrap() {
+			if _, ok := transitions[state][c]; ok {
+				continue
+			}
+			connect(state, c, sink)
+			states.Add(sink)
+		}
+	}
+	if states.In(sink) {
+		for _, c := range alphabet.Unwrap() {
+			connect(sink, c, sink)
+		}
+	}
+	return &dfaMachine{
+		states:       states,
+		chars:        completedChars(s.chars, alphabet),
+		startState:   s.startState,
+		acceptStates: s.acceptStates.Clone(),
+		transitions:  transitions,
+		currentState: s.currentState,
+	}
+}
+
+func (s dfaMachine) Trim() DFAMachine {
+	var (
+		useful      = s.reachableStates().And(s.coReachableStates())
+		transitions = map[string]map[rune]string{}
+		sink        = uniqueName(s.states, sinkState)
+	)
+	useful.Add(s.startState)
+	states := s.states.And(useful)
+	for fromState, x := range s.transitions {
+		if !useful.In(fromState) {
+			continue
+		}
+		var (
+			routes  = map[rune]string{}
+			removed []rune
+		)
+		for c, toState := range x {
+			if useful.In(toState) {
+				routes[c] = toState
+				continue
+			}
+			if c != Otherwise {
+				removed = append(removed, c)
+			}
+		}
+		// the otherwise transition should not be taken by the chars of the removed transitions
+		if _, ok := routes[Otherwise]; ok {
+			for _, c := range removed {
+				routes[c] = sink
+				states.Add(sink)
+			}
+		}
+		if len(routes) > 0 {
+			transitions[fromState] = routes
+		}
+	}
+	current := s.currentState
+	if !useful.In(current) {
+		current = s.startState
+	}
+	return &dfaMachine{
+		states:       states,
+		chars:        s.chars.Clone(),
+		startState:   s.startState,
+		acceptStates: s.acceptStates.And(useful),
+		transitions:  transitions,
+		currentState: current,
+	}
+}
+
+// coReachableStates returns the states from which an accept state is reachable.
+func (s dfaMachine) coReachableStates() set.StringSet {
+	reversed := map[string][]string{}
+	for fromState, x := range s.transitions {
+		for _, toState := range x {
+			reversed[toState] = append(reversed[toState], fromState)
+		}
+	}
+	var (
+		states = s.acceptStates.Clone()
+		q      = s.acceptStates.Unwrap()
+	)
+	for len(q) > 0 {
+		state := q[0]
+		q = q[1:]
+		for _, x := range reversed[state] {
+			if states.In(x) {
+				continue
+			}
+			states.Add(x)
+			q = append(q, x)
+		}
+	}
+	return states
+}
+
+// cloneTransitions returns a deep copy of the transitions.
+func (s dfaMachine) cloneTransitions() map[string]map[rune]string {
+	ts := make(map[string]map[rune]string, len(s.transitions))
+	for fromState, x := range s.transitions {
+		ts[fromState] = make(map[rune]string, len(x))
+		for c, toState := range x {
+			ts[fromState][c] = toState
+		}
+	}
+	return ts
+}
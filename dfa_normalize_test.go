@@ -0,0 +1,108 @@
+package roughfa_test
+
+import (
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+type dfaNormalizeTestcase struct {
+	name         string
+	states       []string
+	chars        []rune
+	acceptStates []string
+	transitions  map[string]map[rune]string
+	// wantComplete is the states after Complete
+	wantComplete []string
+	// wantTrim is the states after Trim
+	wantTrim []string
+}
+
+func (s dfaNormalizeTestcase) test(t *testing.T) {
+	build := func() (roughfa.DFAMachine, error) {
+		return roughfa.NewDFAMachineBuilder().
+			States(s.states).
+			Chars(s.chars).
+			StartState(s.states[0]).
+			AcceptStates(s.acceptStates).
+			Transitions(s.transitions).
+			Build()
+	}
+	m, err := build()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	c := m.Complete()
+	cs := c.ToShell()
+	assert.ElementsMatch(t, s.wantComplete, cs.States, "complete")
+	for _, x := range cs.States {
+		for _, r := range cs.Chars {
+			_, ok := cs.Transitions[x][r]
+			assert.True(t, ok, "complete %s %c", x, r)
+		}
+	}
+	ok, w := roughfa.EquivalentDFA(m, c)
+	assert.True(t, ok, "complete equivalent, distinguished by %q", w)
+
+	tr := m.Trim()
+	assert.ElementsMatch(t, s.wantTrim, tr.ToShell().States, "trim")
+	ok, w = roughfa.EquivalentDFA(m, tr)
+	assert.True(t, ok, "trim equivalent, distinguished by %q", w)
+
+	n, err := build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	nt := roughfa.FromDFA(n).Trim()
+	if s.acceptStates == nil {
+		// the start state is removed from the nfa
+		assert.Equal(t, 0, len(nt.ToShell().States), "nfa trim")
+		return
+	}
+	assert.ElementsMatch(t, s.wantTrim, nt.ToShell().States, "nfa trim")
+	assert.ElementsMatch(t, s.wantComplete, roughfa.FromDFA(n).Complete().ToShell().States, "nfa complete")
+}
+
+func TestDFANormalize(t *testing.T) {
+	for _, tc := range []*dfaNormalizeTestcase{
+		{
+			name:         "complete already",
+			states:       []string{"even", "odd"},
+			chars:        []rune{'0', '1'},
+			acceptStates: []string{"odd"},
+			transitions: map[string]map[rune]string{
+				"even": {'0': "even", '1': "odd"},
+				"odd":  {'0': "odd", '1': "even"},
+			},
+			wantComplete: []string{"even", "odd"},
+			wantTrim:     []string{"even", "odd"},
+		},
+		{
+			name:         "partial",
+			states:       []string{"s", "t", "sink", "island", "dead"},
+			chars:        []rune{'a', 'b'},
+			acceptStates: []string{"t", "island"},
+			transitions: map[string]map[rune]string{
+				"s":      {'a': "t", 'b': "dead"},
+				"t":      {'a': "s"},
+				"island": {'a': "t", 'b': "t"},
+				"dead":   {'a': "dead", 'b': "dead"},
+			},
+			wantComplete: []string{"s", "t", "sink", "island", "dead", "sink'"},
+			wantTrim:     []string{"s", "t"},
+		},
+		{
+			name:   "empty language",
+			states: []string{"s", "t"},
+			transitions: map[string]map[rune]string{
+				"s": {'a': "t"},
+			},
+			wantComplete: []string{"s", "t", "sink"},
+			wantTrim:     []string{"s"},
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
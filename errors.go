@@ -18,6 +18,14 @@ var (
 	ErrEmptyStates            = errors.New("empty states")
 	ErrInvalidStartStates     = errors.New("invalid start states")
 	ErrNoDotSource            = errors.New("no dot source")
+	ErrMissingParen           = errors.New("missing closing )")
+	ErrUnexpectedParen        = errors.New("unexpected )")
+	ErrMissingRepeatArgument  = errors.New("missing argument to repetition operator")
+	ErrTrailingBackslash      = errors.New("trailing backslash at end of expression")
+	ErrInvalidEscape          = errors.New("invalid escape sequence")
+	ErrInvalidRegexpChar      = errors.New("invalid character in expression")
+	ErrInfiniteLanguage       = errors.New("infinite language")
+	ErrOtherwiseExists        = errors.New("otherwise exists")
 )
 
 type (
@@ -26,7 +34,35 @@ type (
 		Stderr string
 		Err    error
 	}
+	// RegexpError represents a parsing error of the regular expression.
+	RegexpError struct {
+		Pattern string
+		// Pos is the byte offset of the error in Pattern.
+		Pos int
+		Err error
+	}
+	// FeedError represents an error while feeding the input.
+	FeedError struct {
+		// Offset is the byte offset of the failing character.
+		Offset int
+		// RuneOffset is the rune offset of the failing character.
+		RuneOffset int
+		// Char is the failing character, or zero if the error is not caused by a character.
+		Char rune
+		Err  error
+	}
 )
 
 func (s RenderError) Error() string { return fmt.Sprintf("%s: %s", s.Err.Error(), s.Stderr) }
 func (s RenderError) Unwrap() error { return s.Err }
+func (s RegexpError) Error() string {
+	return fmt.Sprintf("%s at position %d: %q", s.Err.Error(), s.Pos, s.Pattern)
+}
+func (s RegexpError) Unwrap() error { return s.Err }
+func (s FeedError) Error() string {
+	if s.Char == 0 {
+		return fmt.Sprintf("%s at offset %d (rune %d)", s.Err.Error(), s.Offset, s.RuneOffset)
+	}
+	return fmt.Sprintf("%s at offset %d (rune %d): %q", s.Err.Error(), s.Offset, s.RuneOffset, s.Char)
+}
+func (s FeedError) Unwrap() error { return s.Err }
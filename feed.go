@@ -0,0 +1,126 @@
+package roughfa
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// FeedStop is a set of the conditions to stop feeding before the end of the input.
+type FeedStop int
+
+const (
+	// NoFeedStop reads the input until the end.
+	NoFeedStop FeedStop = 0
+	// AcceptedFeedStop stops when the machine accepts the read prefix, including the empty prefix.
+	AcceptedFeedStop FeedStop = 1 << 0
+	// DeadFeedStop stops when the machine enters the states that cannot reach an accept state,
+	// or has no transitions by the character, that is ErrOutOfTransition or ErrEmptyStates without DeadFeedStop.
+	DeadFeedStop FeedStop = 1 << 1
+)
+
+type (
+	// FeedResult is a result of feeding.
+	FeedResult struct {
+		// Offset is the number of the read bytes.
+		Offset int
+		// RuneOffset is the number of the read runes.
+		RuneOffset int
+		// Stop is the condition that stopped feeding, NoFeedStop if the input ended.
+		Stop FeedStop
+		// Accepted is true if the machine accepts the read input.
+		Accepted bool
+	}
+
+	// feeder puts the input into a machine.
+	feeder struct {
+		put        func(x rune) error
+		isAccepted func() bool
+		// isDead returns true if the machine cannot reach an accept state.
+		isDead func() bool
+	}
+)
+
+func (s *dfaMachine) Feed(ctx context.Context, r io.RuneReader, stop FeedStop) (*FeedResult, error) {
+	f := &feeder{
+		put: s.Put,
+		// s.IsAccepted binds a copy of s
+		isAccepted: func() bool { return s.IsAccepted() },
+	}
+	if stop&DeadFeedStop != 0 {
+		live := s.coReachableStates()
+		f.isDead = func() bool { return !live.In(s.currentState) }
+	}
+	return f.feed(ctx, r, stop)
+}
+
+func (s *nfaMachine) Feed(ctx context.Context, r io.RuneReader, stop FeedStop) (*FeedResult, error) {
+	f := &feeder{
+		put:        s.Put,
+		isAccepted: func() bool { return s.IsAccepted() },
+	}
+	if stop&DeadFeedStop != 0 {
+		live := s.coReachableStates()
+		f.isDead = func() bool { return s.epsilonClosure(s.currentStates).And(live).Len() == 0 }
+	}
+	return f.feed(ctx, r, stop)
+}
+
+func (s feeder) feed(ctx context.Context, r io.RuneReader, stop FeedStop) (*FeedResult, error) {
+	var (
+		result   = &FeedResult{}
+		newError = func(c rune, err error) error {
+			return &FeedError{
+				Offset:     result.Offset,
+				RuneOffset: result.RuneOffset,
+				Char:       c,
+				Err:        err,
+			}
+		}
+		isStopped = func() bool {
+			result.Accepted = s.isAccepted()
+			switch {
+			case stop&AcceptedFeedStop != 0 && result.Accepted:
+				result.Stop = AcceptedFeedStop
+			case stop&DeadFeedStop != 0 && s.isDead():
+				result.Stop = DeadFeedStop
+			default:
+				return false
+			}
+			return true
+		}
+	)
+	if isStopped() {
+		return result, nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return result, newError(0, ctx.Err())
+		default:
+		}
+		c, size, err := r.ReadRune()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, newError(0, err)
+		}
+		if err := s.put(c); err != nil {
+			if stop&DeadFeedStop != 0 && (errors.Is(err, ErrEmptyStates) || errors.Is(err, ErrOutOfTransition)) {
+				// no transitions is also dead
+				result.Offset += size
+				result.RuneOffset++
+				result.Accepted = false
+				result.Stop = DeadFeedStop
+				return result, nil
+			}
+			return result, newError(c, err)
+		}
+		result.Offset += size
+		result.RuneOffset++
+		if isStopped() {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package roughfa_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+type feedTestcase struct {
+	name         string
+	input        string
+	stop         roughfa.FeedStop
+	want         *roughfa.FeedResult
+	wantErr      error
+	wantErrChar  rune
+	wantErrRunes int
+}
+
+func (s feedTestcase) test(t *testing.T) {
+	// accepts the words that have xyz, over x, y, z and あ
+	d, err := roughfa.NewDFAMachineBuilder().
+		States([]string{"0", "1", "2", "3", "dead"}).
+		Chars([]rune{'x', 'y', 'z', 'あ', '!'}).
+		StartState("0").
+		AcceptStates([]string{"3"}).
+		Transitions(map[string]map[rune]string{
+			"0":    {'x': "1", 'y': "0", 'z': "0", 'あ': "0", '!': "dead"},
+			"1":    {'x': "1", 'y': "2", 'z': "0", 'あ': "0", '!': "dead"},
+			"2":    {'x': "1", 'y': "0", 'z': "3", 'あ': "0", '!': "dead"},
+			"3":    {'x': "3", 'y': "3", 'z': "3", 'あ': "3"},
+			"dead": {'x': "dead"},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, m := range []struct {
+		name string
+		feed func(ctx context.Context, r io.RuneReader, stop roughfa.FeedStop) (*roughfa.FeedResult, error)
+	}{
+		{name: "dfa", feed: d.Feed},
+		{name: "nfa", feed: roughfa.FromDFA(d).Feed},
+		{name: "dfa cursor", feed: roughfa.NewDefinition(d).NewCursor().Feed},
+		{name: "nfa cursor", feed: roughfa.NewDefinition(roughfa.FromDFA(d)).NewCursor().Feed},
+	} {
+		m := m
+		t.Run(m.name, func(t *testing.T) {
+			got, err := m.feed(context.Background(), strings.NewReader(s.input), s.stop)
+			assert.Equal(t, s.want, got)
+			if s.wantErr == nil {
+				assert.Nil(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, s.wantErr)
+			var e *roughfa.FeedError
+			if !assert.True(t, errors.As(err, &e)) {
+				return
+			}
+			assert.Equal(t, s.want.Offset, e.Offset)
+			assert.Equal(t, s.wantErrRunes, e.RuneOffset)
+			assert.Equal(t, s.wantErrChar, e.Char)
+		})
+	}
+}
+
+func TestFeedStop(t *testing.T) {
+	assert.Equal(t, roughfa.FeedStop(1), roughfa.AcceptedFeedStop)
+	assert.Equal(t, roughfa.FeedStop(2), roughfa.DeadFeedStop)
+}
+
+func TestFeed(t *testing.T) {
+	for _, tc := range []*feedTestcase{
+		{
+			name:  "end of input",
+			input: "あxyzあ",
+			want: &roughfa.FeedResult{
+				Offset:     9,
+				RuneOffset: 5,
+				Accepted:   true,
+			},
+		},
+		{
+			name:  "stop at accepted",
+			input: "あxyzあ",
+			stop:  roughfa.AcceptedFeedStop | roughfa.DeadFeedStop,
+			want: &roughfa.FeedResult{
+				Offset:     6,
+				RuneOffset: 4,
+				Stop:       roughfa.AcceptedFeedStop,
+				Accepted:   true,
+			},
+		},
+		{
+			name:  "stop at dead",
+			input: "x!xx",
+			stop:  roughfa.AcceptedFeedStop | roughfa.DeadFeedStop,
+			want: &roughfa.FeedResult{
+				Offset:     2,
+				RuneOffset: 2,
+				Stop:       roughfa.DeadFeedStop,
+			},
+		},
+		{
+			name:  "invalid char",
+			input: "あxa",
+			want: &roughfa.FeedResult{
+				Offset:     4,
+				RuneOffset: 2,
+			},
+			wantErr:      roughfa.ErrInvalidInputChar,
+			wantErrChar:  'a',
+			wantErrRunes: 2,
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestFeedNoTransitions(t *testing.T) {
+	d, err := roughfa.NewDFAMachineBuilder().
+		States([]string{"0", "1", "2"}).
+		StartState("0").
+		AcceptStates([]string{"2"}).
+		Transitions(map[string]map[rune]string{
+			"0": {'a': "1"},
+			"1": {'b': "2"},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	n, err := roughfa.CompileRegexp("ab")
+	if !assert.Nil(t, err) {
+		return
+	}
+	type feed func(ctx context.Context, r io.RuneReader, stop roughfa.FeedStop) (*roughfa.FeedResult, error)
+	var (
+		dd = roughfa.NewDefinition(d)
+		nd = roughfa.NewDefinition(n)
+	)
+	for _, tc := range []struct {
+		name    string
+		newFeed func() feed
+		wantErr error
+	}{
+		{name: "partial dfa", newFeed: func() feed { return dd.ToMachine().Feed }, wantErr: roughfa.ErrOutOfTransition},
+		{name: "partial dfa cursor", newFeed: func() feed { return dd.NewCursor().Feed }, wantErr: roughfa.ErrOutOfTransition},
+		{name: "nfa", newFeed: func() feed { return nd.ToMachine().Feed }, wantErr: roughfa.ErrEmptyStates},
+		{name: "nfa cursor", newFeed: func() feed { return nd.NewCursor().Feed }, wantErr: roughfa.ErrEmptyStates},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.newFeed()(context.Background(), strings.NewReader("acb"), roughfa.DeadFeedStop)
+			assert.Nil(t, err)
+			assert.Equal(t, &roughfa.FeedResult{
+				Offset:     2,
+				RuneOffset: 2,
+				Stop:       roughfa.DeadFeedStop,
+			}, got)
+
+			got, err = tc.newFeed()(context.Background(), strings.NewReader("acb"), roughfa.NoFeedStop)
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, &roughfa.FeedResult{
+				Offset:     1,
+				RuneOffset: 1,
+			}, got)
+		})
+	}
+}
+
+func TestFeedCancel(t *testing.T) {
+	m, err := roughfa.CompileRegexp("a*")
+	if !assert.Nil(t, err) {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	got, err := m.Feed(ctx, strings.NewReader("aaa"), roughfa.NoFeedStop)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, &roughfa.FeedResult{Accepted: true}, got)
+}
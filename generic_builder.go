@@ -0,0 +1,178 @@
+package roughfa
+
+import "github.com/berquerant/roughfa/internal/set"
+
+type (
+	// GenericDFAMachineBuilder is a builder of GenericDFAMachine.
+	GenericDFAMachineBuilder[S, Q comparable] interface {
+		// States configures the states of the machine.
+		// Required.
+		States(states []Q) GenericDFAMachineBuilder[S, Q]
+		// StartState configures the initial state of the machine.
+		// Required.
+		// Must be included in States.
+		StartState(state Q) GenericDFAMachineBuilder[S, Q]
+		// AcceptStates configures the accept states of the machine.
+		// Required.
+		// Must be a subset of States.
+		AcceptStates(acceptStates []Q) GenericDFAMachineBuilder[S, Q]
+		// Transitions configures the transition function of the machine.
+		// Required.
+		Transitions(transitions map[Q]map[S]Q) GenericDFAMachineBuilder[S, Q]
+		// Build creates a new GenericDFAMachine.
+		// Returns an error if some validations fails.
+		Build() (GenericDFAMachine[S, Q], error)
+	}
+
+	genericDFAMachineBuilder[S, Q comparable] struct {
+		states       []Q
+		startState   Q
+		acceptStates []Q
+		transitions  map[Q]map[S]Q
+	}
+
+	// GenericNFAMachineBuilder is a builder of GenericNFAMachine.
+	GenericNFAMachineBuilder[S, Q comparable] interface {
+		// States configures the states of the machine.
+		// Required.
+		States(states []Q) GenericNFAMachineBuilder[S, Q]
+		// StartStates configures the initial states of the machine.
+		// Required.
+		// Must be included in States.
+		StartStates(startStates []Q) GenericNFAMachineBuilder[S, Q]
+		// AcceptStates configures the accept states of the machine.
+		// Required.
+		// Must be a subset of States.
+		AcceptStates(acceptStates []Q) GenericNFAMachineBuilder[S, Q]
+		// Transitions configures the transition function of the machine.
+		Transitions(transitions map[Q]map[S][]Q) GenericNFAMachineBuilder[S, Q]
+		// EpsilonTransitions configures the epsilon transitions of the machine.
+		EpsilonTransitions(transitions map[Q][]Q) GenericNFAMachineBuilder[S, Q]
+		// Build creates a new GenericNFAMachine.
+		// Returns an error if some validations fails.
+		Build() (GenericNFAMachine[S, Q], error)
+	}
+
+	genericNFAMachineBuilder[S, Q comparable] struct {
+		states             []Q
+		startStates        []Q
+		acceptStates       []Q
+		transitions        map[Q]map[S][]Q
+		epsilonTransitions map[Q][]Q
+	}
+)
+
+// NewGenericDFAMachineBuilder creates a new GenericDFAMachineBuilder.
+func NewGenericDFAMachineBuilder[S, Q comparable]() GenericDFAMachineBuilder[S, Q] {
+	return &genericDFAMachineBuilder[S, Q]{}
+}
+
+func (s *genericDFAMachineBuilder[S, Q]) States(states []Q) GenericDFAMachineBuilder[S, Q] {
+	s.states = states
+	return s
+}
+func (s *genericDFAMachineBuilder[S, Q]) StartState(state Q) GenericDFAMachineBuilder[S, Q] {
+	s.startState = state
+	return s
+}
+func (s *genericDFAMachineBuilder[S, Q]) AcceptStates(acceptStates []Q) GenericDFAMachineBuilder[S, Q] {
+	s.acceptStates = acceptStates
+	return s
+}
+func (s *genericDFAMachineBuilder[S, Q]) Transitions(transitions map[Q]map[S]Q) GenericDFAMachineBuilder[S, Q] {
+	s.transitions = transitions
+	return s
+}
+func (s genericDFAMachineBuilder[S, Q]) Build() (GenericDFAMachine[S, Q], error) {
+	states := set.NewSet(s.states...)
+	if !states.In(s.startState) {
+		return nil, ErrInvalidStartState
+	}
+	if !states.In(s.acceptStates...) {
+		return nil, ErrInvalidAcceptStates
+	}
+	transitions := make(map[Q]map[S]Q, len(s.transitions))
+	for fromState, x := range s.transitions {
+		if !states.In(fromState) {
+			return nil, ErrInvalidTransitions
+		}
+		transitions[fromState] = make(map[S]Q, len(x))
+		for c, toState := range x {
+			if !states.In(toState) {
+				return nil, ErrInvalidTransitions
+			}
+			transitions[fromState][c] = toState
+		}
+	}
+	return &genericDFAMachine[S, Q]{
+		states:       states,
+		startState:   s.startState,
+		acceptStates: set.NewSet(s.acceptStates...),
+		transitions:  transitions,
+		currentState: s.startState,
+	}, nil
+}
+
+// NewGenericNFAMachineBuilder creates a new GenericNFAMachineBuilder.
+func NewGenericNFAMachineBuilder[S, Q comparable]() GenericNFAMachineBuilder[S, Q] {
+	return &genericNFAMachineBuilder[S, Q]{}
+}
+
+func (s *genericNFAMachineBuilder[S, Q]) States(states []Q) GenericNFAMachineBuilder[S, Q] {
+	s.states = states
+	return s
+}
+func (s *genericNFAMachineBuilder[S, Q]) StartStates(startStates []Q) GenericNFAMachineBuilder[S, Q] {
+	s.startStates = startStates
+	return s
+}
+func (s *genericNFAMachineBuilder[S, Q]) AcceptStates(acceptStates []Q) GenericNFAMachineBuilder[S, Q] {
+	s.acceptStates = acceptStates
+	return s
+}
+func (s *genericNFAMachineBuilder[S, Q]) Transitions(transitions map[Q]map[S][]Q) GenericNFAMachineBuilder[S, Q] {
+	s.transitions = transitions
+	return s
+}
+func (s *genericNFAMachineBuilder[S, Q]) EpsilonTransitions(transitions map[Q][]Q) GenericNFAMachineBuilder[S, Q] {
+	s.epsilonTransitions = transitions
+	return s
+}
+func (s genericNFAMachineBuilder[S, Q]) Build() (GenericNFAMachine[S, Q], error) {
+	states := set.NewSet(s.states...)
+	if len(s.startStates) == 0 || !states.In(s.startStates...) {
+		return nil, ErrInvalidStartStates
+	}
+	if !states.In(s.acceptStates...) {
+		return nil, ErrInvalidAcceptStates
+	}
+	transitions := make(map[Q]map[S]set.Set[Q], len(s.transitions))
+	for fromState, x := range s.transitions {
+		if !states.In(fromState) {
+			return nil, ErrInvalidTransitions
+		}
+		transitions[fromState] = make(map[S]set.Set[Q], len(x))
+		for c, toStates := range x {
+			if !states.In(toStates...) {
+				return nil, ErrInvalidTransitions
+			}
+			transitions[fromState][c] = set.NewSet(toStates...)
+		}
+	}
+	epsilonTransitions := make(map[Q]set.Set[Q], len(s.epsilonTransitions))
+	for fromState, toStates := range s.epsilonTransitions {
+		if !states.In(fromState) || !states.In(toStates...) {
+			return nil, ErrInvalidTransitions
+		}
+		epsilonTransitions[fromState] = set.NewSet(toStates...)
+	}
+	m := &genericNFAMachine[S, Q]{
+		states:             states,
+		startStates:        set.NewSet(s.startStates...),
+		acceptStates:       set.NewSet(s.acceptStates...),
+		transitions:        transitions,
+		epsilonTransitions: epsilonTransitions,
+	}
+	m.Reset()
+	return m, nil
+}
@@ -0,0 +1,271 @@
+package roughfa
+
+import (
+	"encoding/json"
+
+	"github.com/berquerant/roughfa/internal/set"
+)
+
+type (
+	// GenericDFAMachineShell is a serializable form of GenericDFAMachine.
+	// The states and the symbols are encoded by encoding/json,
+	// and the transitions are encoded as the list of the edges.
+	GenericDFAMachineShell[S, Q comparable] struct {
+		States       []Q
+		StartState   Q
+		AcceptStates []Q
+		Transitions  map[Q]map[S]Q
+		CurrentState Q
+	}
+
+	genericDFAMachineShellJSON[S, Q comparable] struct {
+		States       []Q                         `json:"states"`
+		StartState   Q                           `json:"start_state"`
+		AcceptStates []Q                         `json:"accept_states"`
+		Transitions  []*genericDFAEdgeJSON[S, Q] `json:"transitions"`
+		CurrentState Q                           `json:"current_state"`
+	}
+
+	genericDFAEdgeJSON[S, Q comparable] struct {
+		From   Q `json:"from"`
+		Symbol S `json:"symbol"`
+		To     Q `json:"to"`
+	}
+
+	// GenericNFAMachineShell is a serializable form of GenericNFAMachine.
+	// See GenericDFAMachineShell for the json format.
+	GenericNFAMachineShell[S, Q comparable] struct {
+		States             []Q
+		StartStates        []Q
+		AcceptStates       []Q
+		Transitions        map[Q]map[S][]Q
+		EpsilonTransitions map[Q][]Q
+		CurrentStates      []Q
+	}
+
+	genericNFAMachineShellJSON[S, Q comparable] struct {
+		States             []Q                         `json:"states"`
+		StartStates        []Q                         `json:"start_states"`
+		AcceptStates       []Q                         `json:"accept_states"`
+		Transitions        []*genericNFAEdgeJSON[S, Q] `json:"transitions"`
+		EpsilonTransitions []*genericNFAEdgeJSON[S, Q] `json:"epsilon_transitions,omitempty"`
+		CurrentStates      []Q                         `json:"current_states"`
+	}
+
+	// genericNFAEdgeJSON is an edge of the nfa, Symbol is omitted if the edge is an epsilon transition.
+	genericNFAEdgeJSON[S, Q comparable] struct {
+		From   Q   `json:"from"`
+		Symbol *S  `json:"symbol,omitempty"`
+		To     []Q `json:"to"`
+	}
+)
+
+func (s genericDFAMachine[S, Q]) ToShell() *GenericDFAMachineShell[S, Q] {
+	t := make(map[Q]map[S]Q, len(s.transitions))
+	for k, x := range s.transitions {
+		t[k] = make(map[S]Q, len(x))
+		for c, toState := range x {
+			t[k][c] = toState
+		}
+	}
+	return &GenericDFAMachineShell[S, Q]{
+		States:       s.states.Unwrap(),
+		StartState:   s.startState,
+		AcceptStates: s.acceptStates.Unwrap(),
+		Transitions:  t,
+		CurrentState: s.currentState,
+	}
+}
+
+func (s GenericDFAMachineShell[S, Q]) ToJSON() ([]byte, error) {
+	var edges []*genericDFAEdgeJSON[S, Q]
+	for _, fromState := range s.orderedStates() {
+		x := s.Transitions[fromState]
+		for _, c := range sortedSymbols(symbolsOf(x)) {
+			edges = append(edges, &genericDFAEdgeJSON[S, Q]{
+				From:   fromState,
+				Symbol: c,
+				To:     x[c],
+			})
+		}
+	}
+	return json.Marshal(genericDFAMachineShellJSON[S, Q]{
+		States:       s.States,
+		StartState:   s.StartState,
+		AcceptStates: s.AcceptStates,
+		Transitions:  edges,
+		CurrentState: s.CurrentState,
+	})
+}
+
+// orderedStates returns the states in the order of States and the other states of the transitions.
+func (s GenericDFAMachineShell[S, Q]) orderedStates() []Q {
+	xs := set.NewSet(s.States...)
+	for k := range s.Transitions {
+		xs.Add(k)
+	}
+	return xs.Unwrap()
+}
+
+// NewGenericDFAMachineShellFromJSON creates a GenericDFAMachineShell from the json.
+// Returns an error if the edges from a state by a symbol go to the different states.
+func NewGenericDFAMachineShellFromJSON[S, Q comparable](b []byte) (*GenericDFAMachineShell[S, Q], error) {
+	var s genericDFAMachineShellJSON[S, Q]
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	t := map[Q]map[S]Q{}
+	for _, e := range s.Transitions {
+		if _, ok := t[e.From]; !ok {
+			t[e.From] = map[S]Q{}
+		}
+		if x, ok := t[e.From][e.Symbol]; ok && x != e.To {
+			return nil, ErrCannotUnmarshalMachine
+		}
+		t[e.From][e.Symbol] = e.To
+	}
+	return &GenericDFAMachineShell[S, Q]{
+		States:       s.States,
+		StartState:   s.StartState,
+		AcceptStates: s.AcceptStates,
+		Transitions:  t,
+		CurrentState: s.CurrentState,
+	}, nil
+}
+
+func (s GenericDFAMachineShell[S, Q]) ToMachine() (GenericDFAMachine[S, Q], error) {
+	m, err := NewGenericDFAMachineBuilder[S, Q]().
+		States(s.States).
+		StartState(s.StartState).
+		AcceptStates(s.AcceptStates).
+		Transitions(s.Transitions).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.SetState(s.CurrentState); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s genericNFAMachine[S, Q]) ToShell() *GenericNFAMachineShell[S, Q] {
+	t := make(map[Q]map[S][]Q, len(s.transitions))
+	for k, x := range s.transitions {
+		t[k] = make(map[S][]Q, len(x))
+		for c, toStates := range x {
+			t[k][c] = toStates.Unwrap()
+		}
+	}
+	e := make(map[Q][]Q, len(s.epsilonTransitions))
+	for k, x := range s.epsilonTransitions {
+		e[k] = x.Unwrap()
+	}
+	return &GenericNFAMachineShell[S, Q]{
+		States:             s.states.Unwrap(),
+		StartStates:        s.startStates.Unwrap(),
+		AcceptStates:       s.acceptStates.Unwrap(),
+		Transitions:        t,
+		EpsilonTransitions: e,
+		CurrentStates:      s.currentStates.Unwrap(),
+	}
+}
+
+func (s GenericNFAMachineShell[S, Q]) ToJSON() ([]byte, error) {
+	var edges, epsilons []*genericNFAEdgeJSON[S, Q]
+	for _, fromState := range s.orderedStates() {
+		x := s.Transitions[fromState]
+		for _, c := range sortedSymbols(symbolsOf(x)) {
+			c := c
+			edges = append(edges, &genericNFAEdgeJSON[S, Q]{
+				From:   fromState,
+				Symbol: &c,
+				To:     x[c],
+			})
+		}
+		if toStates, ok := s.EpsilonTransitions[fromState]; ok {
+			epsilons = append(epsilons, &genericNFAEdgeJSON[S, Q]{
+				From: fromState,
+				To:   toStates,
+			})
+		}
+	}
+	return json.Marshal(genericNFAMachineShellJSON[S, Q]{
+		States:             s.States,
+		StartStates:        s.StartStates,
+		AcceptStates:       s.AcceptStates,
+		Transitions:        edges,
+		EpsilonTransitions: epsilons,
+		CurrentStates:      s.CurrentStates,
+	})
+}
+
+// orderedStates returns the states in the order of States and the other states of the transitions.
+func (s GenericNFAMachineShell[S, Q]) orderedStates() []Q {
+	xs := set.NewSet(s.States...)
+	for k := range s.Transitions {
+		xs.Add(k)
+	}
+	for k := range s.EpsilonTransitions {
+		xs.Add(k)
+	}
+	return xs.Unwrap()
+}
+
+// NewGenericNFAMachineShellFromJSON creates a GenericNFAMachineShell from the json.
+func NewGenericNFAMachineShellFromJSON[S, Q comparable](b []byte) (*GenericNFAMachineShell[S, Q], error) {
+	var s genericNFAMachineShellJSON[S, Q]
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	t := map[Q]map[S][]Q{}
+	for _, e := range s.Transitions {
+		if e.Symbol == nil {
+			return nil, ErrCannotUnmarshalMachine
+		}
+		if _, ok := t[e.From]; !ok {
+			t[e.From] = map[S][]Q{}
+		}
+		t[e.From][*e.Symbol] = append(t[e.From][*e.Symbol], e.To...)
+	}
+	eps := map[Q][]Q{}
+	for _, e := range s.EpsilonTransitions {
+		eps[e.From] = append(eps[e.From], e.To...)
+	}
+	return &GenericNFAMachineShell[S, Q]{
+		States:             s.States,
+		StartStates:        s.StartStates,
+		AcceptStates:       s.AcceptStates,
+		Transitions:        t,
+		EpsilonTransitions: eps,
+		CurrentStates:      s.CurrentStates,
+	}, nil
+}
+
+func (s GenericNFAMachineShell[S, Q]) ToMachine() (GenericNFAMachine[S, Q], error) {
+	m, err := NewGenericNFAMachineBuilder[S, Q]().
+		States(s.States).
+		StartStates(s.StartStates).
+		AcceptStates(s.AcceptStates).
+		Transitions(s.Transitions).
+		EpsilonTransitions(s.EpsilonTransitions).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	if s.CurrentStates != nil {
+		if err := m.SetStates(s.CurrentStates); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// symbolsOf returns the keys of the map.
+func symbolsOf[S comparable, T any](x map[S]T) []S {
+	xs := make([]S, 0, len(x))
+	for c := range x {
+		xs = append(xs, c)
+	}
+	return xs
+}
@@ -0,0 +1,459 @@
+package roughfa
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/berquerant/roughfa/internal/dot"
+	"github.com/berquerant/roughfa/internal/set"
+)
+
+type (
+	// GenericDFAMachine is a runner of the deterministic finite automaton
+	// whose input symbols are S and states are Q.
+	// See ToGenericDFA to convert a DFAMachine.
+	GenericDFAMachine[S, Q comparable] interface {
+		// State returns the current state.
+		State() Q
+		// SetState sets the state as the current state.
+		// Returns an error if the state is not in the states.
+		SetState(state Q) error
+		// Put inputs a symbol.
+		// Returns an error if no transitions.
+		Put(x S) error
+		// IsAccepted returns true if the current state is in the accept states.
+		IsAccepted() bool
+		// Reset resets the current state to the start state.
+		Reset()
+		// Accepts returns true if this accepts the input.
+		// Accepts runs from the start without changing the current state.
+		Accepts(input []S) bool
+		// Minimize creates the minimal dfa that accepts the same language.
+		// Returns the minimal dfa and the map from the states of this to the states of the minimal dfa.
+		// A state of the minimal dfa is named the first of the equivalent states in the order of the states.
+		// The states that are unreachable or cannot reach an accept state are not in the map,
+		// except the start state.
+		Minimize() (GenericDFAMachine[S, Q], map[Q]Q)
+		// ToNFA creates a GenericNFAMachine that has the same transitions.
+		ToNFA() GenericNFAMachine[S, Q]
+		// ToShell generates GenericDFAMachineShell.
+		ToShell() *GenericDFAMachineShell[S, Q]
+		// ToDot generates Dot.
+		// The states and the symbols are formatted by fmt.Sprint.
+		ToDot() (dot.Dot, error)
+	}
+
+	genericDFAMachine[S, Q comparable] struct {
+		states       set.Set[Q]
+		startState   Q
+		acceptStates set.Set[Q]
+		transitions  map[Q]map[S]Q
+		currentState Q
+	}
+
+	// GenericNFAMachine is a runner of the non deterministic finite automaton
+	// whose input symbols are S and states are Q.
+	// See ToGenericNFA to convert a NFAMachine.
+	GenericNFAMachine[S, Q comparable] interface {
+		// States returns the current states.
+		// The current states are closed under the epsilon transitions.
+		States() []Q
+		// SetStates sets the states as the current states.
+		// Returns an error if the states are not in the states.
+		SetStates(states []Q) error
+		// Put inputs a symbol.
+		// Returns an error if no transitions.
+		Put(x S) error
+		// IsAccepted returns true if the current states contain an accept state.
+		IsAccepted() bool
+		// Reset resets the current states to the start states.
+		Reset()
+		// Accepts returns true if this accepts the input.
+		// Accepts runs from the start without changing the current states.
+		Accepts(input []S) bool
+		// Reverse creates a GenericNFAMachine that accepts the reversed words.
+		Reverse() GenericNFAMachine[S, Q]
+		// Determinize creates a dfa by the powerset construction.
+		// The states of the dfa are numbered from 0, the start state.
+		// Returns the dfa and the map from the states of the dfa to the subsets of the states of this.
+		Determinize() (GenericDFAMachine[S, int], map[int][]Q)
+		// Minimize creates the minimal dfa by the algorithm of Brzozowski.
+		// The states of the dfa are numbered from 0, the start state.
+		Minimize() GenericDFAMachine[S, int]
+		// ToShell generates GenericNFAMachineShell.
+		ToShell() *GenericNFAMachineShell[S, Q]
+		// ToDot generates Dot.
+		// The states and the symbols are formatted by fmt.Sprint.
+		ToDot() (dot.Dot, error)
+	}
+
+	genericNFAMachine[S, Q comparable] struct {
+		states             set.Set[Q]
+		startStates        set.Set[Q]
+		acceptStates       set.Set[Q]
+		transitions        map[Q]map[S]set.Set[Q]
+		epsilonTransitions map[Q]set.Set[Q]
+		currentStates      set.Set[Q]
+	}
+)
+
+func (s genericDFAMachine[S, Q]) State() Q { return s.currentState }
+func (s *genericDFAMachine[S, Q]) SetState(state Q) error {
+	if !s.states.In(state) {
+		return ErrInvalidState
+	}
+	s.currentState = state
+	return nil
+}
+func (s *genericDFAMachine[S, Q]) Put(x S) error {
+	next, ok := s.transitions[s.currentState][x]
+	if !ok {
+		return ErrOutOfTransition
+	}
+	s.currentState = next
+	return nil
+}
+func (s genericDFAMachine[S, Q]) IsAccepted() bool { return s.acceptStates.In(s.currentState) }
+func (s *genericDFAMachine[S, Q]) Reset()          { s.currentState = s.startState }
+func (s genericDFAMachine[S, Q]) Accepts(input []S) bool {
+	state := s.startState
+	for _, x := range input {
+		next, ok := s.transitions[state][x]
+		if !ok {
+			return false
+		}
+		state = next
+	}
+	return s.acceptStates.In(state)
+}
+
+func (s genericDFAMachine[S, Q]) Minimize() (GenericDFAMachine[S, Q], map[Q]Q) {
+	var (
+		reachable = s.reachableStates()
+		names     = make([]Q, 0, reachable.Len())
+	)
+	// keep the order of the states
+	for _, x := range s.states.Unwrap() {
+		if reachable.In(x) {
+			names = append(names, x)
+		}
+	}
+	r := minimizeDFA(names, s.symbols(), s.startState,
+		func(q Q, c S) (Q, bool) {
+			y, ok := s.transitions[q][c]
+			return y, ok
+		},
+		func(q Q) bool { return s.acceptStates.In(q) },
+	)
+	return &genericDFAMachine[S, Q]{
+		states:       set.NewSet(r.states...),
+		startState:   r.startState,
+		acceptStates: set.NewSet(r.acceptStates...),
+		transitions:  r.transitions,
+		currentState: r.startState,
+	}, r.mapping
+}
+
+// reachableStates returns the states reachable from the start state.
+func (s genericDFAMachine[S, Q]) reachableStates() set.Set[Q] {
+	var (
+		states = set.NewSet(s.startState)
+		q      = []Q{s.startState}
+	)
+	for len(q) > 0 {
+		x := q[0]
+		q = q[1:]
+		for _, y := range s.transitions[x] {
+			if !states.In(y) {
+				states.Add(y)
+				q = append(q, y)
+			}
+		}
+	}
+	return states
+}
+
+// symbols returns the symbols of the transitions.
+func (s genericDFAMachine[S, Q]) symbols() []S {
+	xs := set.NewSet[S]()
+	for _, x := range s.transitions {
+		for c := range x {
+			xs.Add(c)
+		}
+	}
+	return sortedSymbols(xs.Unwrap())
+}
+
+func (s genericDFAMachine[S, Q]) ToNFA() GenericNFAMachine[S, Q] {
+	transitions := make(map[Q]map[S]set.Set[Q], len(s.transitions))
+	for fromState, x := range s.transitions {
+		transitions[fromState] = make(map[S]set.Set[Q], len(x))
+		for c, toState := range x {
+			transitions[fromState][c] = set.NewSet(toState)
+		}
+	}
+	return &genericNFAMachine[S, Q]{
+		states:             s.states.Clone(),
+		startStates:        set.NewSet(s.startState),
+		acceptStates:       s.acceptStates.Clone(),
+		transitions:        transitions,
+		epsilonTransitions: map[Q]set.Set[Q]{},
+		currentStates:      set.NewSet(s.currentState),
+	}
+}
+
+func (s genericDFAMachine[S, Q]) ToDot() (dot.Dot, error) {
+	t := map[string]map[string][]string{}
+	for fromState, x := range s.transitions {
+		from := fmt.Sprint(fromState)
+		t[from] = make(map[string][]string, len(x))
+		for c, toState := range x {
+			label := fmt.Sprint(c)
+			t[from][label] = append(t[from][label], fmt.Sprint(toState))
+		}
+	}
+	return dot.NewSymbolicDotBuilder().
+		StartStates([]string{fmt.Sprint(s.startState)}).
+		States(formatStates(s.states.Unwrap())).
+		AcceptStates(formatStates(s.acceptStates.Unwrap())).
+		Transitions(t).
+		Build()
+}
+
+func (s genericNFAMachine[S, Q]) States() []Q { return s.currentStates.Unwrap() }
+func (s *genericNFAMachine[S, Q]) SetStates(states []Q) error {
+	if !s.states.In(states...) {
+		return ErrInvalidState
+	}
+	s.currentStates = s.closure(set.NewSet(states...))
+	return nil
+}
+func (s *genericNFAMachine[S, Q]) Put(x S) error {
+	if s.currentStates.Len() == 0 {
+		return ErrEmptyStates
+	}
+	s.currentStates = s.next(s.currentStates, x)
+	if s.currentStates.Len() == 0 {
+		return ErrEmptyStates
+	}
+	return nil
+}
+func (s genericNFAMachine[S, Q]) IsAccepted() bool { return s.isAccepted(s.currentStates) }
+func (s *genericNFAMachine[S, Q]) Reset()          { s.currentStates = s.closure(s.startStates) }
+func (s genericNFAMachine[S, Q]) Accepts(input []S) bool {
+	states := s.closure(s.startStates)
+	for _, x := range input {
+		if states = s.next(states, x); states.Len() == 0 {
+			return false
+		}
+	}
+	return s.isAccepted(states)
+}
+
+func (s genericNFAMachine[S, Q]) isAccepted(states set.Set[Q]) bool {
+	for _, x := range states.Unwrap() {
+		if s.acceptStates.In(x) {
+			return true
+		}
+	}
+	return false
+}
+
+// closure returns the states reachable from the states by the epsilon transitions.
+func (s genericNFAMachine[S, Q]) closure(states set.Set[Q]) set.Set[Q] {
+	var (
+		result = states.Clone()
+		q      = states.Unwrap()
+	)
+	for len(q) > 0 {
+		x := q[0]
+		q = q[1:]
+		y, ok := s.epsilonTransitions[x]
+		if !ok {
+			continue
+		}
+		for _, z := range y.Unwrap() {
+			if !result.In(z) {
+				result.Add(z)
+				q = append(q, z)
+			}
+		}
+	}
+	return result
+}
+
+// next returns the closure of the destinations from the states by x.
+func (s genericNFAMachine[S, Q]) next(states set.Set[Q], x S) set.Set[Q] {
+	result := set.NewSet[Q]()
+	for _, state := range states.Unwrap() {
+		if y, ok := s.transitions[state][x]; ok {
+			result.Add(y.Unwrap()...)
+		}
+	}
+	return s.closure(result)
+}
+
+// symbols returns the symbols of the transitions.
+func (s genericNFAMachine[S, Q]) symbols() []S {
+	xs := set.NewSet[S]()
+	for _, x := range s.transitions {
+		for c := range x {
+			xs.Add(c)
+		}
+	}
+	return sortedSymbols(xs.Unwrap())
+}
+
+func (s genericNFAMachine[S, Q]) Reverse() GenericNFAMachine[S, Q] {
+	var (
+		transitions        = map[Q]map[S]set.Set[Q]{}
+		epsilonTransitions = map[Q]set.Set[Q]{}
+	)
+	for fromState, x := range s.transitions {
+		for c, toStates := range x {
+			for _, toState := range toStates.Unwrap() {
+				if _, ok := transitions[toState]; !ok {
+					transitions[toState] = map[S]set.Set[Q]{}
+				}
+				if _, ok := transitions[toState][c]; !ok {
+					transitions[toState][c] = set.NewSet[Q]()
+				}
+				transitions[toState][c].Add(fromState)
+			}
+		}
+	}
+	for fromState, toStates := range s.epsilonTransitions {
+		for _, toState := range toStates.Unwrap() {
+			if _, ok := epsilonTransitions[toState]; !ok {
+				epsilonTransitions[toState] = set.NewSet[Q]()
+			}
+			epsilonTransitions[toState].Add(fromState)
+		}
+	}
+	m := &genericNFAMachine[S, Q]{
+		states:             s.states.Clone(),
+		startStates:        s.acceptStates.Clone(),
+		acceptStates:       s.startStates.Clone(),
+		transitions:        transitions,
+		epsilonTransitions: epsilonTransitions,
+	}
+	m.Reset()
+	return m
+}
+
+func (s genericNFAMachine[S, Q]) Determinize() (GenericDFAMachine[S, int], map[int][]Q) {
+	var (
+		index = map[Q]int{}
+		// subsets[i] is the subset of the state i of the dfa
+		subsets      [][]Q
+		numbers      = map[string]int{}
+		acceptStates = set.NewSet[int]()
+		transitions  = map[int]map[S]int{}
+		alphabet     = s.symbols()
+		// numberOf returns the state of the dfa of the subset
+		numberOf = func(x set.Set[Q]) (int, bool) {
+			xs := x.Unwrap()
+			sort.Slice(xs, func(i, j int) bool { return index[xs[i]] < index[xs[j]] })
+			keys := make([]string, len(xs))
+			for i, y := range xs {
+				keys[i] = fmt.Sprint(index[y])
+			}
+			k := strings.Join(keys, ",")
+			if n, ok := numbers[k]; ok {
+				return n, false
+			}
+			n := len(subsets)
+			numbers[k] = n
+			subsets = append(subsets, xs)
+			if s.isAccepted(x) {
+				acceptStates.Add(n)
+			}
+			return n, true
+		}
+	)
+	for i, x := range s.states.Unwrap() {
+		index[x] = i
+	}
+	start, _ := numberOf(s.closure(s.startStates))
+	for i := 0; i < len(subsets); i++ {
+		from := set.NewSet(subsets[i]...)
+		for _, c := range alphabet {
+			next := s.next(from, c)
+			if next.Len() == 0 {
+				continue
+			}
+			to, _ := numberOf(next)
+			if _, ok := transitions[i]; !ok {
+				transitions[i] = map[S]int{}
+			}
+			transitions[i][c] = to
+		}
+	}
+	var (
+		states = set.NewSet[int]()
+		result = make(map[int][]Q, len(subsets))
+	)
+	for i, x := range subsets {
+		states.Add(i)
+		result[i] = x
+	}
+	return &genericDFAMachine[S, int]{
+		states:       states,
+		startState:   start,
+		acceptStates: acceptStates,
+		transitions:  transitions,
+		currentState: start,
+	}, result
+}
+
+func (s genericNFAMachine[S, Q]) Minimize() GenericDFAMachine[S, int] {
+	d, _ := s.Reverse().Determinize()
+	m, _ := d.ToNFA().Reverse().Determinize()
+	return m
+}
+
+func (s genericNFAMachine[S, Q]) ToDot() (dot.Dot, error) {
+	var (
+		t       = map[string]map[string][]string{}
+		addEdge = func(fromState Q, label string, toStates set.Set[Q]) {
+			from := fmt.Sprint(fromState)
+			if _, ok := t[from]; !ok {
+				t[from] = map[string][]string{}
+			}
+			t[from][label] = append(t[from][label], formatStates(toStates.Unwrap())...)
+		}
+	)
+	for fromState, x := range s.transitions {
+		for c, toStates := range x {
+			addEdge(fromState, fmt.Sprint(c), toStates)
+		}
+	}
+	for fromState, toStates := range s.epsilonTransitions {
+		addEdge(fromState, string(dot.Epsilon), toStates)
+	}
+	return dot.NewSymbolicDotBuilder().
+		StartStates(formatStates(s.startStates.Unwrap())).
+		States(formatStates(s.states.Unwrap())).
+		AcceptStates(formatStates(s.acceptStates.Unwrap())).
+		Transitions(t).
+		Build()
+}
+
+// sortedSymbols sorts the symbols by the formatted strings for the deterministic order.
+func sortedSymbols[S comparable](xs []S) []S {
+	keys := make(map[S]string, len(xs))
+	for _, x := range xs {
+		keys[x] = fmt.Sprint(x)
+	}
+	sort.SliceStable(xs, func(i, j int) bool { return keys[xs[i]] < keys[xs[j]] })
+	return xs
+}
+
+func formatStates[Q comparable](xs []Q) []string {
+	ys := make([]string, len(xs))
+	for i, x := range xs {
+		ys[i] = fmt.Sprint(x)
+	}
+	return ys
+}
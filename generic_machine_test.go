@@ -0,0 +1,265 @@
+package roughfa_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+// newWorkflowMachine creates a dfa that accepts the events until the approval.
+func newWorkflowMachine() (roughfa.GenericDFAMachine[string, string], error) {
+	return roughfa.NewGenericDFAMachineBuilder[string, string]().
+		States([]string{"draft", "review", "revision", "approved"}).
+		StartState("draft").
+		AcceptStates([]string{"approved"}).
+		Transitions(map[string]map[string]string{
+			"draft": {
+				"submit": "review",
+			},
+			"review": {
+				"approve": "approved",
+				"reject":  "revision",
+			},
+			"revision": {
+				"submit": "review",
+			},
+		}).
+		Build()
+}
+
+func TestGenericDFAMachine(t *testing.T) {
+	m, err := newWorkflowMachine()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Nil(t, m.Put("submit"))
+	assert.Equal(t, "review", m.State())
+	assert.Equal(t, roughfa.ErrOutOfTransition, m.Put("submit"))
+	assert.Nil(t, m.Put("approve"))
+	assert.True(t, m.IsAccepted())
+	m.Reset()
+	assert.Equal(t, "draft", m.State())
+	assert.Equal(t, roughfa.ErrInvalidState, m.SetState("done"))
+
+	assert.True(t, m.Accepts([]string{"submit", "reject", "submit", "approve"}))
+	assert.False(t, m.Accepts([]string{"submit", "reject"}))
+
+	// draft and revision are equivalent
+	min, mapping := m.Minimize()
+	assert.Equal(t, []string{"draft", "review", "approved"}, min.ToShell().States)
+	assert.Equal(t, map[string]string{
+		"draft":    "draft",
+		"review":   "review",
+		"revision": "draft",
+		"approved": "approved",
+	}, mapping)
+	assert.True(t, min.Accepts([]string{"submit", "reject", "submit", "approve"}))
+
+	b, err := m.ToShell().ToJSON()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, strings.Contains(string(b), `{"from":"review","symbol":"approve","to":"approved"}`), string(b))
+	shell, err := roughfa.NewGenericDFAMachineShellFromJSON[string, string](b)
+	if !assert.Nil(t, err) {
+		return
+	}
+	restored, err := shell.ToMachine()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, m.ToShell(), restored.ToShell())
+
+	d, err := m.ToDot()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, strings.Contains(d.AsDot(), `label="approve"`), d.AsDot())
+}
+
+func TestGenericNFAMachine(t *testing.T) {
+	// accepts the sequences of 1 and 2 whose second to last is 1
+	m, err := roughfa.NewGenericNFAMachineBuilder[int, int]().
+		States([]int{10, 20, 30, 40}).
+		StartStates([]int{10}).
+		AcceptStates([]int{40}).
+		Transitions(map[int]map[int][]int{
+			20: {
+				1: {20, 30},
+				2: {20},
+			},
+			30: {
+				1: {40},
+				2: {40},
+			},
+		}).
+		EpsilonTransitions(map[int][]int{
+			10: {20},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []int{10, 20}, m.States())
+	assert.Nil(t, m.Put(1))
+	assert.Nil(t, m.Put(2))
+	assert.True(t, m.IsAccepted())
+
+	var (
+		accept = func(w []int) bool { return len(w) >= 2 && w[len(w)-2] == 1 }
+		words  = [][]int{{}}
+	)
+	for i := 0; i < 4; i++ {
+		for _, w := range words {
+			if len(w) == i {
+				words = append(words, append(append([]int{}, w...), 1), append(append([]int{}, w...), 2))
+			}
+		}
+	}
+
+	d, subsets := m.Determinize()
+	assert.Equal(t, []int{10, 20}, subsets[0])
+	assert.Equal(t, 5, len(subsets))
+	min := m.Minimize()
+	assert.Equal(t, 4, len(min.ToShell().States))
+	r := m.Reverse()
+	for _, w := range words {
+		assert.Equal(t, accept(w), m.Accepts(w), "%v", w)
+		assert.Equal(t, accept(w), d.Accepts(w), "dfa %v", w)
+		assert.Equal(t, accept(w), min.Accepts(w), "minimal %v", w)
+		reversed := make([]int, len(w))
+		for i, x := range w {
+			reversed[len(w)-1-i] = x
+		}
+		assert.Equal(t, accept(w), r.Accepts(reversed), "reverse %v", w)
+	}
+
+	b, err := m.ToShell().ToJSON()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, strings.Contains(string(b), `"epsilon_transitions":[{"from":10,"to":[20]}]`), string(b))
+	shell, err := roughfa.NewGenericNFAMachineShellFromJSON[int, int](b)
+	if !assert.Nil(t, err) {
+		return
+	}
+	restored, err := shell.ToMachine()
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, w := range words {
+		assert.Equal(t, accept(w), restored.Accepts(w), "restored %v", w)
+	}
+}
+
+func TestGenericRuneMachine(t *testing.T) {
+	m, err := roughfa.CompileRegexp("(a|b)*abb")
+	if !assert.Nil(t, err) {
+		return
+	}
+	g, err := roughfa.ToGenericNFA(m)
+	if !assert.Nil(t, err) {
+		return
+	}
+	min := g.Minimize()
+	assert.Equal(t, 4, len(min.ToShell().States))
+	back, err := roughfa.FromGenericNFA(g)
+	if !assert.Nil(t, err) {
+		return
+	}
+	d, err := roughfa.FromGenericDFA(min)
+	if !assert.Nil(t, err) {
+		return
+	}
+	gd, err := roughfa.ToGenericDFA(d)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, w := range allWords("ab", 5) {
+		want := m.Accepts(w)
+		assert.Equal(t, want, g.Accepts([]rune(w)), "%q", w)
+		assert.Equal(t, want, min.Accepts([]rune(w)), "minimal %q", w)
+		assert.Equal(t, want, back.Accepts(w), "back %q", w)
+		assert.Equal(t, want, d.Accepts(w), "dfa %q", w)
+		assert.Equal(t, want, gd.Accepts([]rune(w)), "generic dfa %q", w)
+	}
+}
+
+func TestGenericRuneMachineOtherwise(t *testing.T) {
+	t.Run("universe", func(t *testing.T) {
+		m, err := newCommentMachine(nil)
+		if !assert.Nil(t, err) {
+			return
+		}
+		_, err = roughfa.ToGenericDFA(m)
+		assert.Equal(t, roughfa.ErrOtherwiseExists, err)
+		_, err = roughfa.ToGenericNFA(roughfa.FromDFA(m))
+		assert.Equal(t, roughfa.ErrOtherwiseExists, err)
+	})
+	t.Run("chars", func(t *testing.T) {
+		m, err := newCommentMachine([]rune{'/', '\n', 'x', roughfa.Otherwise})
+		if !assert.Nil(t, err) {
+			return
+		}
+		d, err := roughfa.ToGenericDFA(m)
+		if !assert.Nil(t, err) {
+			return
+		}
+		n, err := roughfa.ToGenericNFA(roughfa.FromDFA(m))
+		if !assert.Nil(t, err) {
+			return
+		}
+		for _, w := range allWords("/\nxy", 4) {
+			want := m.Accepts(w)
+			assert.Equal(t, want, d.Accepts([]rune(w)), "dfa %q", w)
+			assert.Equal(t, want, n.Accepts([]rune(w)), "nfa %q", w)
+		}
+		assert.True(t, d.Accepts([]rune("x")))
+	})
+}
+
+func TestGenericRuneMinimize(t *testing.T) {
+	// 1 and 2 are equivalent, and d cannot reach the accept state
+	m, err := roughfa.NewDFAMachineBuilder().
+		States([]string{"0", "1", "2", "3", "d"}).
+		StartState("0").
+		AcceptStates([]string{"3"}).
+		Transitions(map[string]map[rune]string{
+			"0": {
+				'a': "1",
+				'b': "2",
+				'c': "d",
+			},
+			"1": {
+				'a': "3",
+			},
+			"2": {
+				'a': "3",
+			},
+			"d": {
+				'a': "d",
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	g, err := roughfa.ToGenericDFA(m)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var (
+		min, mapping   = m.Minimize()
+		gmin, gmapping = g.Minimize()
+		shell          = min.ToShell()
+		gshell         = gmin.ToShell()
+	)
+	assert.Equal(t, []string{"0", "1", "3"}, gshell.States)
+	assert.ElementsMatch(t, shell.States, gshell.States)
+	assert.Equal(t, shell.StartState, gshell.StartState)
+	assert.ElementsMatch(t, shell.AcceptStates, gshell.AcceptStates)
+	assert.Equal(t, shell.Transitions, gshell.Transitions)
+	assert.Equal(t, mapping, gmapping)
+}
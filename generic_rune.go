@@ -0,0 +1,139 @@
+package roughfa
+
+import (
+	"fmt"
+
+	"github.com/berquerant/roughfa/internal/set"
+)
+
+// ToGenericDFA converts the DFAMachine into GenericDFAMachine[rune, string].
+// The chars are dropped because the characters out of the chars have no transitions.
+// The otherwise transitions are expanded into the transitions by the chars,
+// so returns ErrOtherwiseExists if the chars are universe and the machine has the otherwise transitions,
+// because the generic machine has no transitions by the symbols that appear in no transitions.
+func ToGenericDFA(m DFAMachine) (GenericDFAMachine[rune, string], error) {
+	x := asDFAMachine(m).expandOtherwise().ToShell()
+	ts := make(map[string]map[rune]string, len(x.Transitions))
+	for k, v := range x.Transitions {
+		if _, ok := v[Otherwise]; ok {
+			return nil, ErrOtherwiseExists
+		}
+		ts[k] = v
+	}
+	return &genericDFAMachine[rune, string]{
+		states:       set.NewSet(sortedStrings(set.NewStringSet(x.States...))...),
+		startState:   x.StartState,
+		acceptStates: set.NewSet(x.AcceptStates...),
+		transitions:  ts,
+		currentState: x.CurrentState,
+	}, nil
+}
+
+// FromGenericDFA converts the GenericDFAMachine[rune, Q] into DFAMachine.
+// The states are named by fmt.Sprint.
+// Returns ErrInvalidState if the names of the states are duplicated.
+func FromGenericDFA[Q comparable](m GenericDFAMachine[rune, Q]) (DFAMachine, error) {
+	var (
+		x    = m.ToShell()
+		name = func(q Q) string { return fmt.Sprint(q) }
+		ts   = make(map[string]map[rune]string, len(x.Transitions))
+	)
+	states, err := formatUniqueStates(x.States)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range x.Transitions {
+		ts[name(k)] = make(map[rune]string, len(v))
+		for c, toState := range v {
+			ts[name(k)][c] = name(toState)
+		}
+	}
+	return DFAMachineShell{
+		States:       states,
+		StartState:   name(x.StartState),
+		AcceptStates: formatStates(x.AcceptStates),
+		Transitions:  ts,
+		CurrentState: name(x.CurrentState),
+	}.ToMachine()
+}
+
+// ToGenericNFA converts the NFAMachine into GenericNFAMachine[rune, string].
+// The transitions by Epsilon are the epsilon transitions.
+// See ToGenericDFA for the chars and the otherwise transitions.
+func ToGenericNFA(m NFAMachine) (GenericNFAMachine[rune, string], error) {
+	var (
+		x                  = asNFAMachine(m).expandOtherwise().ToShell()
+		transitions        = map[string]map[rune]set.Set[string]{}
+		epsilonTransitions = map[string]set.Set[string]{}
+	)
+	for k, v := range x.Transitions {
+		for c, toStates := range v {
+			switch c {
+			case Otherwise:
+				return nil, ErrOtherwiseExists
+			case Epsilon:
+				epsilonTransitions[k] = set.NewSet(toStates...)
+				continue
+			}
+			if _, ok := transitions[k]; !ok {
+				transitions[k] = map[rune]set.Set[string]{}
+			}
+			transitions[k][c] = set.NewSet(toStates...)
+		}
+	}
+	g := &genericNFAMachine[rune, string]{
+		states:             set.NewSet(sortedStrings(set.NewStringSet(x.States...))...),
+		startStates:        set.NewSet(x.StartStates...),
+		acceptStates:       set.NewSet(x.AcceptStates...),
+		transitions:        transitions,
+		epsilonTransitions: epsilonTransitions,
+	}
+	g.currentStates = g.closure(set.NewSet(x.CurrentStates...))
+	return g, nil
+}
+
+// FromGenericNFA converts the GenericNFAMachine[rune, Q] into NFAMachine.
+// The epsilon transitions are the transitions by Epsilon.
+// See FromGenericDFA for the names of the states.
+func FromGenericNFA[Q comparable](m GenericNFAMachine[rune, Q]) (NFAMachine, error) {
+	var (
+		x  = m.ToShell()
+		ts = make(map[string]map[rune][]string, len(x.Transitions))
+		at = func(k Q) map[rune][]string {
+			name := fmt.Sprint(k)
+			if _, ok := ts[name]; !ok {
+				ts[name] = map[rune][]string{}
+			}
+			return ts[name]
+		}
+	)
+	states, err := formatUniqueStates(x.States)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range x.Transitions {
+		for c, toStates := range v {
+			at(k)[c] = formatStates(toStates)
+		}
+	}
+	for k, toStates := range x.EpsilonTransitions {
+		at(k)[Epsilon] = formatStates(toStates)
+	}
+	return NFAMachineShell{
+		States:        states,
+		StartStates:   formatStates(x.StartStates),
+		AcceptStates:  formatStates(x.AcceptStates),
+		Transitions:   ts,
+		CurrentStates: formatStates(x.CurrentStates),
+	}.ToMachine()
+}
+
+// formatUniqueStates formats the states by fmt.Sprint.
+// Returns ErrInvalidState if the formatted states are duplicated.
+func formatUniqueStates[Q comparable](xs []Q) ([]string, error) {
+	ys := formatStates(xs)
+	if set.NewStringSet(ys...).Len() != len(ys) {
+		return nil, ErrInvalidState
+	}
+	return ys, nil
+}
@@ -35,7 +35,7 @@ func (s attr) AsDot() string {
 	case RawAttrType:
 		return fmt.Sprintf("%s=%s", s.Name(), s.Value())
 	case WrappedAttrType:
-		return fmt.Sprintf(`%s="%s"`, s.Name(), s.Value())
+		return fmt.Sprintf("%s=%s", s.Name(), quote(s.Value()))
 	default:
 		panic("unknown attr type")
 	}
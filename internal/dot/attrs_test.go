@@ -48,6 +48,13 @@ func TestAttr(t *testing.T) {
 			attrType: dot.WrappedAttrType,
 			want:     `color="#000000"`,
 		},
+		{
+			cname:    "escaped",
+			name:     "label",
+			value:    `"\`,
+			attrType: dot.WrappedAttrType,
+			want:     `label="\"\\"`,
+		},
 	} {
 		t.Run(tc.cname, tc.test)
 	}
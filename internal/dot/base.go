@@ -132,7 +132,7 @@ func (s node) Name() string { return s.name }
 func (s node) Attrs() Attrs { return s.attrs }
 func (s node) AsDot() string {
 	b := NewStringBuilder()
-	b.Write(s.Name())
+	b.Write(QuoteID(s.Name()))
 	if s.Attrs().Len() > 0 {
 		b.Write(" ")
 		b.Write(s.Attrs().AsDot())
@@ -188,7 +188,7 @@ func NewEdge(start, end Node) (Edge, error) {
 
 func (s edge) AsDot() string {
 	b := NewStringBuilder()
-	b.Write(fmt.Sprintf("%s -> %s", s.start.Name(), s.end.Name()))
+	b.Write(fmt.Sprintf("%s -> %s", QuoteID(s.start.Name()), QuoteID(s.end.Name())))
 	if s.attrs.Len() > 0 {
 		b.Write(" ")
 		b.Write(s.attrs.AsDot())
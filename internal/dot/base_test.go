@@ -149,6 +149,12 @@ func TestEdge(t *testing.T) {
 			},
 			want: "n1 -> n2 [a1]",
 		},
+		{
+			name:  "quoted",
+			start: newMockNode("", "a-start", nil),
+			end:   newMockNode("", "{0,1}", nil),
+			want:  `"a-start" -> "{0,1}"`,
+		},
 	} {
 		t.Run(tc.name, tc.test)
 	}
@@ -193,6 +199,16 @@ func TestNode(t *testing.T) {
 			},
 			want: "n [a1]",
 		},
+		{
+			cname: "numeral",
+			name:  "-1.5",
+			want:  "-1.5",
+		},
+		{
+			cname: "quoted",
+			name:  `(p,"q")`,
+			want:  `"(p,\"q\")"`,
+		},
 	} {
 		t.Run(tc.cname, tc.test)
 	}
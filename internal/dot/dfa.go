@@ -10,6 +10,9 @@ type (
 		AcceptStates(acceptStates []string) DFADotBuilder
 		// Transitions sets the transition map.
 		Transitions(transitions map[string]map[rune]string) DFADotBuilder
+		// Labels sets the labels of the states.
+		// The name of the state is used as the label if the state is not in the labels.
+		Labels(labels map[string]string) DFADotBuilder
 		// Build generates Dot.
 		// Returns an error if some contradictions exist.
 		Build() (Dot, error)
@@ -36,13 +39,21 @@ func (s *dfaDotBuilder) AcceptStates(acceptStates []string) DFADotBuilder {
 	return s
 }
 func (s *dfaDotBuilder) Transitions(transitions map[string]map[rune]string) DFADotBuilder {
-	t := make(map[string]map[rune][]string, len(transitions))
+	t := make(map[string]map[string][]string, len(transitions))
 	for k, v := range transitions {
-		t[k] = make(map[rune][]string, len(v))
+		t[k] = make(map[string][]string, len(v))
 		for kx, kv := range v {
-			t[k][kx] = []string{kv}
+			t[k][edgeLabel(kx)] = []string{kv}
 		}
 	}
 	s.transitions = t
 	return s
 }
+func (s *dfaDotBuilder) Labels(labels map[string]string) DFADotBuilder {
+	t := make(map[string]string, len(labels))
+	for k, v := range labels {
+		t[k] = v
+	}
+	s.labels = t
+	return s
+}
@@ -5,11 +5,27 @@ type (
 		startStates  []string
 		states       []string
 		acceptStates []string
-		transitions  map[string]map[rune][]string
+		// transitions is the map from the state to the map from the edge label to the destinations
+		transitions map[string]map[string][]string
+		// labels are the labels of the states, the name of the state is used if no label
+		labels map[string]string
 	}
 )
 
-func (baseFaDotBuilder) newAcceptState(state string) (Node, error) {
+func (s baseFaDotBuilder) addLabel(n Node, state string) error {
+	label, ok := s.labels[state]
+	if !ok {
+		return nil
+	}
+	a, err := NewAttrBuilder().Name("label").Value(label).Build()
+	if err != nil {
+		return err
+	}
+	n.Attrs().Add(a)
+	return nil
+}
+
+func (s baseFaDotBuilder) newAcceptState(state string) (Node, error) {
 	n, err := NewNode(state)
 	if err != nil {
 		return nil, err
@@ -19,10 +35,13 @@ func (baseFaDotBuilder) newAcceptState(state string) (Node, error) {
 		return nil, err
 	}
 	n.Attrs().Add(a)
+	if err := s.addLabel(n, state); err != nil {
+		return nil, err
+	}
 	return n, nil
 }
 
-func (baseFaDotBuilder) newNormalState(state string) (Node, error) {
+func (s baseFaDotBuilder) newNormalState(state string) (Node, error) {
 	n, err := NewNode(state)
 	if err != nil {
 		return nil, err
@@ -32,6 +51,9 @@ func (baseFaDotBuilder) newNormalState(state string) (Node, error) {
 		return nil, err
 	}
 	n.Attrs().Add(a)
+	if err := s.addLabel(n, state); err != nil {
+		return nil, err
+	}
 	return n, nil
 }
 
@@ -139,7 +161,7 @@ func (s baseFaDotBuilder) Build() (Dot, error) {
 				if !ok {
 					return nil, ErrInvalidEdge
 				}
-				e, err := s.newEdge(start, end, string(to))
+				e, err := s.newEdge(start, end, to)
 				if err != nil {
 					return nil, err
 				}
@@ -149,3 +171,11 @@ func (s baseFaDotBuilder) Build() (Dot, error) {
 	}
 	return g, nil
 }
+
+// edgeLabel returns the label of the transition by c.
+func edgeLabel(c rune) string {
+	if c == Otherwise {
+		return OtherwiseLabel
+	}
+	return string(c)
+}
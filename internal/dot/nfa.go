@@ -3,6 +3,10 @@ package dot
 const (
 	// Epsilon is for the epsilon transition.
 	Epsilon = 'ε'
+	// Otherwise is for the otherwise transition.
+	Otherwise = '\uFFFF'
+	// OtherwiseLabel is the label of the otherwise transition.
+	OtherwiseLabel = "else"
 )
 
 type (
@@ -15,6 +19,9 @@ type (
 		AcceptStates(acceptStates []string) NFADotBuilder
 		// Transitions sets the transition map.
 		Transitions(transitions map[string]map[rune][]string) NFADotBuilder
+		// Labels sets the labels of the states.
+		// The name of the state is used as the label if the state is not in the labels.
+		Labels(labels map[string]string) NFADotBuilder
 		// Build generates Dot.
 		// Returns an error if some contradictions exist.
 		Build() (Dot, error)
@@ -41,13 +48,21 @@ func (s *nfaDotBuilder) AcceptStates(acceptStates []string) NFADotBuilder {
 	return s
 }
 func (s *nfaDotBuilder) Transitions(transitions map[string]map[rune][]string) NFADotBuilder {
-	t := make(map[string]map[rune][]string, len(transitions))
+	t := make(map[string]map[string][]string, len(transitions))
 	for k, v := range transitions {
-		t[k] = make(map[rune][]string, len(v))
+		t[k] = make(map[string][]string, len(v))
 		for kx, kv := range v {
-			t[k][kx] = kv
+			t[k][edgeLabel(kx)] = kv
 		}
 	}
 	s.transitions = t
 	return s
 }
+func (s *nfaDotBuilder) Labels(labels map[string]string) NFADotBuilder {
+	t := make(map[string]string, len(labels))
+	for k, v := range labels {
+		t[k] = v
+	}
+	s.labels = t
+	return s
+}
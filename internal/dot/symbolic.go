@@ -0,0 +1,49 @@
+package dot
+
+type (
+	// SymbolicDotBuilder is a builder of Dot for the machine whose edges are labeled by strings.
+	SymbolicDotBuilder interface {
+		// StartStates sets the start state.
+		StartStates(startState []string) SymbolicDotBuilder
+		// States sets the states.
+		States(states []string) SymbolicDotBuilder
+		// AcceptStates sets the accept states.
+		AcceptStates(acceptStates []string) SymbolicDotBuilder
+		// Transitions sets the transition map whose keys are the labels of the edges.
+		Transitions(transitions map[string]map[string][]string) SymbolicDotBuilder
+		// Build generates Dot.
+		// Returns an error if some contradictions exist.
+		Build() (Dot, error)
+	}
+
+	symbolicDotBuilder struct {
+		baseFaDotBuilder
+	}
+)
+
+// NewSymbolicDotBuilder creates a new SymbolicDotBuilder.
+func NewSymbolicDotBuilder() SymbolicDotBuilder { return &symbolicDotBuilder{} }
+
+func (s *symbolicDotBuilder) StartStates(startStates []string) SymbolicDotBuilder {
+	s.startStates = startStates
+	return s
+}
+func (s *symbolicDotBuilder) States(states []string) SymbolicDotBuilder {
+	s.states = states
+	return s
+}
+func (s *symbolicDotBuilder) AcceptStates(acceptStates []string) SymbolicDotBuilder {
+	s.acceptStates = acceptStates
+	return s
+}
+func (s *symbolicDotBuilder) Transitions(transitions map[string]map[string][]string) SymbolicDotBuilder {
+	t := make(map[string]map[string][]string, len(transitions))
+	for k, v := range transitions {
+		t[k] = make(map[string][]string, len(v))
+		for kx, kv := range v {
+			t[k][kx] = kv
+		}
+	}
+	s.transitions = t
+	return s
+}
@@ -2,6 +2,7 @@ package dot
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/google/uuid"
@@ -73,3 +74,23 @@ func (s stringBuilder) IndentN(n int) error {
 }
 func (s stringBuilder) String() string                  { return s.b.String() }
 func (s stringBuilder) WriteLine(x string) (int, error) { return s.b.WriteString(x + string(newline)) }
+
+var (
+	idPattern      = regexp.MustCompile(`^[A-Za-z_\x{80}-\x{10FFFF}][A-Za-z_0-9\x{80}-\x{10FFFF}]*$`)
+	numeralPattern = regexp.MustCompile(`^-?(\.[0-9]+|[0-9]+(\.[0-9]*)?)$`)
+	quoteReplacer  = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+)
+
+// QuoteID returns id as is if id is a valid ID of dot, otherwise a double-quoted string.
+func QuoteID(id string) string {
+	switch strings.ToLower(id) {
+	case "node", "edge", "graph", "digraph", "subgraph", "strict":
+		return quote(id)
+	}
+	if idPattern.MatchString(id) || numeralPattern.MatchString(id) {
+		return id
+	}
+	return quote(id)
+}
+
+func quote(x string) string { return `"` + quoteReplacer.Replace(x) + `"` }
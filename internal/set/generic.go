@@ -0,0 +1,64 @@
+package set
+
+import "fmt"
+
+type (
+	// Set is a set that remembers the insertion order.
+	Set[T comparable] interface {
+		Add(x ...T)
+		Del(x ...T)
+		In(x ...T) bool
+		Len() int
+		// Unwrap returns the elements in the insertion order.
+		Unwrap() []T
+		Clone() Set[T]
+	}
+
+	orderedSet[T comparable] struct {
+		index map[T]int
+		v     []T
+	}
+)
+
+func NewSet[T comparable](seed ...T) Set[T] {
+	s := &orderedSet[T]{
+		index: map[T]int{},
+	}
+	s.Add(seed...)
+	return s
+}
+
+func (s *orderedSet[T]) Add(x ...T) {
+	for _, p := range x {
+		if _, ok := s.index[p]; ok {
+			continue
+		}
+		s.index[p] = len(s.v)
+		s.v = append(s.v, p)
+	}
+}
+func (s *orderedSet[T]) Del(x ...T) {
+	for _, p := range x {
+		delete(s.index, p)
+	}
+	v := make([]T, 0, len(s.index))
+	for _, p := range s.v {
+		if _, ok := s.index[p]; ok {
+			s.index[p] = len(v)
+			v = append(v, p)
+		}
+	}
+	s.v = v
+}
+func (s orderedSet[T]) In(x ...T) bool {
+	for _, p := range x {
+		if _, ok := s.index[p]; !ok {
+			return false
+		}
+	}
+	return true
+}
+func (s orderedSet[T]) Len() int       { return len(s.v) }
+func (s orderedSet[T]) Unwrap() []T    { return append([]T{}, s.v...) }
+func (s orderedSet[T]) Clone() Set[T]  { return NewSet(s.v...) }
+func (s orderedSet[T]) String() string { return fmt.Sprint(s.v) }
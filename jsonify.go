@@ -0,0 +1,217 @@
+package roughfa
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// JSONShellVersion is the version of the json format written by ToJSON of the shells.
+	//
+	// In the version 2, a state of the transitions is an object
+	// whose "on" is the transitions by the labels, "epsilon" is the epsilon transition
+	// and "otherwise" is the otherwise transition.
+	// A label is a character like "a" or "é", or a range of the characters like "a-z".
+	// A character of the label can be escaped by \\, \-, \n, \r, \t, \uXXXX, \UXXXXXXXX or \x{X...}.
+	// A range is expanded into the characters, so its width is limited to JSONLabelRangeLimit,
+	// the otherwise transition is for the wider ranges.
+	// The labels of a nfa cannot have ε and \uFFFF because they are the epsilon and otherwise transitions,
+	// but ε is an ordinary character for a dfa.
+	// The chars are the labels too, and they may have ε and \uFFFF for the epsilon and otherwise transitions.
+	//
+	// The version 1 has no version field, and the label is a character or EpsilonForJSON for the epsilon transition.
+	// The version 1 has no otherwise transitions.
+	JSONShellVersion = 2
+	// JSONLabelRangeLimit is the maximum number of the characters of a range label.
+	JSONLabelRangeLimit = 1 << 16
+)
+
+type jsonShellHeader struct {
+	Version int `json:"version"`
+}
+
+// jsonShellVersionOf returns the version of the json of a shell.
+func jsonShellVersionOf(b []byte) (int, error) {
+	var h jsonShellHeader
+	if err := json.Unmarshal(b, &h); err != nil {
+		return 0, err
+	}
+	switch h.Version {
+	case 0:
+		return 1, nil
+	case 1, JSONShellVersion:
+		return h.Version, nil
+	default:
+		return 0, fmt.Errorf("%w: version %d", ErrCannotUnmarshalMachine, h.Version)
+	}
+}
+
+// parseJSONLabel returns the characters of the label of the transitions.
+// The label cannot have the reserved characters that have their own fields.
+func parseJSONLabel(label string, reserved ...rune) (RuneRange, error) {
+	r, err := parseJSONLabelRange(label)
+	if err != nil {
+		return RuneRange{}, err
+	}
+	for _, c := range reserved {
+		if r.Lo <= c && c <= r.Hi {
+			return RuneRange{}, invalidJSONLabel(label)
+		}
+	}
+	return r, nil
+}
+
+// parseJSONLabelRange returns the characters of the label.
+func parseJSONLabelRange(label string) (RuneRange, error) {
+	if utf8.RuneCountInString(label) == 1 {
+		// no escapes
+		c, _ := utf8.DecodeRuneInString(label)
+		return RuneRange{Lo: c, Hi: c}, nil
+	}
+	lo, rest, err := parseJSONLabelChar(label, label)
+	if err != nil {
+		return RuneRange{}, err
+	}
+	hi := lo
+	if rest != "" {
+		if rest[0] != '-' {
+			return RuneRange{}, invalidJSONLabel(label)
+		}
+		if hi, rest, err = parseJSONLabelChar(label, rest[1:]); err != nil {
+			return RuneRange{}, err
+		}
+		if rest != "" || lo > hi {
+			return RuneRange{}, invalidJSONLabel(label)
+		}
+		if hi-lo >= JSONLabelRangeLimit {
+			return RuneRange{}, fmt.Errorf("%w: too wide label %q", ErrCannotUnmarshalMachine, label)
+		}
+	}
+	return RuneRange{Lo: lo, Hi: hi}, nil
+}
+
+// parseJSONLabelChar reads a character of the label from s.
+// Returns the character and the rest of s.
+func parseJSONLabelChar(label, s string) (rune, string, error) {
+	c, size := utf8.DecodeRuneInString(s)
+	if c == utf8.RuneError && size <= 1 {
+		return 0, "", invalidJSONLabel(label)
+	}
+	if c != '\\' {
+		return c, s[size:], nil
+	}
+	s = s[size:]
+	if s == "" {
+		return 0, "", invalidJSONLabel(label)
+	}
+	var hex string
+	switch s[0] {
+	case '\\', '-':
+		return rune(s[0]), s[1:], nil
+	case 'n':
+		return '\n', s[1:], nil
+	case 'r':
+		return '\r', s[1:], nil
+	case 't':
+		return '\t', s[1:], nil
+	case 'u':
+		if len(s) < 5 {
+			return 0, "", invalidJSONLabel(label)
+		}
+		hex, s = s[1:5], s[5:]
+	case 'U':
+		if len(s) < 9 {
+			return 0, "", invalidJSONLabel(label)
+		}
+		hex, s = s[1:9], s[9:]
+	case 'x':
+		end := strings.IndexByte(s, '}')
+		if len(s) < 2 || s[1] != '{' || end < 0 {
+			return 0, "", invalidJSONLabel(label)
+		}
+		hex, s = s[2:end], s[end+1:]
+	default:
+		return 0, "", invalidJSONLabel(label)
+	}
+	x, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || x > unicode.MaxRune {
+		return 0, "", invalidJSONLabel(label)
+	}
+	return rune(x), s, nil
+}
+
+func invalidJSONLabel(label string) error {
+	return fmt.Errorf("%w: invalid label %q", ErrCannotUnmarshalMachine, label)
+}
+
+// formatJSONLabel returns the label of the range.
+func formatJSONLabel(r RuneRange) string {
+	if r.Lo == r.Hi {
+		return formatJSONLabelChar(r.Lo)
+	}
+	return formatJSONLabelChar(r.Lo) + "-" + formatJSONLabelChar(r.Hi)
+}
+
+func formatJSONLabelChar(c rune) string {
+	switch {
+	case c == '\\' || c == '-':
+		return `\` + string(c)
+	case unicode.IsPrint(c):
+		return string(c)
+	case c <= 0xFFFF:
+		return fmt.Sprintf(`\u%04X`, c)
+	default:
+		return fmt.Sprintf(`\U%08X`, c)
+	}
+}
+
+// parseJSONChars returns the characters of the labels of the chars.
+func parseJSONChars(labels []string) ([]rune, error) {
+	var cs []rune
+	for _, x := range labels {
+		r, err := parseJSONLabelRange(x)
+		if err != nil {
+			return nil, err
+		}
+		for c := r.Lo; c <= r.Hi; c++ {
+			cs = append(cs, c)
+		}
+	}
+	return cs, nil
+}
+
+// formatJSONLabels returns the labels of the characters, the consecutive characters are merged into a range.
+// The range wider than JSONLabelRangeLimit is split.
+func formatJSONLabels(cs []rune) []string {
+	if len(cs) == 0 {
+		return nil
+	}
+	var labels []string
+	for _, r := range NewRuneClassOf(cs...).Ranges() {
+		for ; r.Hi-r.Lo >= JSONLabelRangeLimit; r.Lo += JSONLabelRangeLimit {
+			labels = append(labels, formatJSONLabel(RuneRange{Lo: r.Lo, Hi: r.Lo + JSONLabelRangeLimit - 1}))
+		}
+		labels = append(labels, formatJSONLabel(r))
+	}
+	return labels
+}
+
+// groupJSONLabels groups the characters by the keys, and returns the labels of each group.
+func groupJSONLabels(cs []rune, key func(c rune) string) map[string][]string {
+	groups := map[string][]rune{}
+	for _, c := range cs {
+		k := key(c)
+		groups[k] = append(groups[k], c)
+	}
+	labels := make(map[string][]string, len(groups))
+	for k, x := range groups {
+		sort.Slice(x, func(i, j int) bool { return x[i] < x[j] })
+		labels[k] = formatJSONLabels(x)
+	}
+	return labels
+}
@@ -0,0 +1,350 @@
+package roughfa_test
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNFAMachineShellFromJSON(t *testing.T) {
+	for _, tc := range []*struct {
+		title  string
+		json   string
+		err    error
+		accept []string
+		reject []string
+	}{
+		{
+			title: "version 1",
+			json: `{
+  "states": ["0", "1", "2"],
+  "start_states": ["0"],
+  "accept_states": ["2"],
+  "transitions": {
+    "0": {"é": ["1"], "\u0007": ["1"]},
+    "1": {"ε": ["2"]}
+  }
+}`,
+			accept: []string{"", "é"},
+			reject: []string{"e", "éé"},
+		},
+		{
+			title: "version 1 backspace",
+			json: `{
+  "states": ["0", "1"],
+  "start_states": ["0"],
+  "accept_states": ["1"],
+  "transitions": {"0": {"\b": ["1"]}}
+}`,
+			accept: []string{"\b"},
+			reject: []string{"z"},
+		},
+		{
+			title: "version 1 multiple characters",
+			json: `{
+  "states": ["0"],
+  "start_states": ["0"],
+  "accept_states": ["0"],
+  "transitions": {"0": {"ab": ["0"]}}
+}`,
+			err: roughfa.ErrCannotUnmarshalMachine,
+		},
+		{
+			title: "version 2",
+			json: `{
+  "version": 2,
+  "states": ["0", "1", "2"],
+  "start_states": ["0"],
+  "accept_states": ["2"],
+  "transitions": {
+    "0": {"on": {"é": ["1"], "\\u00e8": ["1"], "a-c": ["1"], "\\x{1F600}": ["2"]}, "epsilon": ["1"]},
+    "1": {"on": {"\\-": ["2"], "\\\\": ["2"]}, "otherwise": ["0"]}
+  }
+}`,
+			accept: []string{"-", "é-", "è\\", "b-", "😀", "ax-", "d-"},
+			reject: []string{"", "d", "a"},
+		},
+		{
+			title: "version 2 chars",
+			json: `{
+  "version": 2,
+  "states": ["0"],
+  "chars": ["a-c", "\\n"],
+  "start_states": ["0"],
+  "accept_states": ["0"],
+  "transitions": {"0": {"on": {"a-c": ["0"], "\n": ["0"]}}}
+}`,
+			accept: []string{"ab\nc"},
+			reject: []string{"d"},
+		},
+		{
+			title: "invalid range",
+			json: `{
+  "version": 2,
+  "states": ["0"],
+  "start_states": ["0"],
+  "accept_states": ["0"],
+  "transitions": {"0": {"on": {"c-a": ["0"]}}}
+}`,
+			err: roughfa.ErrCannotUnmarshalMachine,
+		},
+		{
+			title: "invalid escape",
+			json: `{
+  "version": 2,
+  "states": ["0"],
+  "start_states": ["0"],
+  "accept_states": ["0"],
+  "transitions": {"0": {"on": {"\\q": ["0"]}}}
+}`,
+			err: roughfa.ErrCannotUnmarshalMachine,
+		},
+		{
+			title: "epsilon label",
+			json: `{
+  "version": 2,
+  "states": ["0"],
+  "start_states": ["0"],
+  "accept_states": ["0"],
+  "transitions": {"0": {"on": {"ε": ["0"]}}}
+}`,
+			err: roughfa.ErrCannotUnmarshalMachine,
+		},
+		{
+			title: "range over epsilon",
+			json: `{
+  "version": 2,
+  "states": ["0"],
+  "start_states": ["0"],
+  "accept_states": ["0"],
+  "transitions": {"0": {"on": {"α-ω": ["0"]}}}
+}`,
+			err: roughfa.ErrCannotUnmarshalMachine,
+		},
+		{
+			title: "too wide range",
+			json: `{
+  "version": 2,
+  "states": ["0"],
+  "start_states": ["0"],
+  "accept_states": ["0"],
+  "transitions": {"0": {"on": {"\\u0000-\\U0010FFFF": ["0"]}}}
+}`,
+			err: roughfa.ErrCannotUnmarshalMachine,
+		},
+		{
+			title: "unknown version",
+			json:  `{"version": 3}`,
+			err:   roughfa.ErrCannotUnmarshalMachine,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			s, err := roughfa.NewNFAMachineShellFromJSON([]byte(tc.json))
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "%v", err)
+				return
+			}
+			if !assert.Nil(t, err) {
+				return
+			}
+			m, err := s.ToMachine()
+			if !assert.Nil(t, err) {
+				return
+			}
+			for _, w := range tc.accept {
+				assert.True(t, m.Accepts(w), "accept %q", w)
+			}
+			for _, w := range tc.reject {
+				assert.False(t, m.Accepts(w), "reject %q", w)
+			}
+		})
+	}
+}
+
+func TestDFAMachineShellFromJSON(t *testing.T) {
+	t.Run("version 1", func(t *testing.T) {
+		s, err := roughfa.NewDFAMachineShellFromJSON([]byte(`{
+  "states": ["0", "1"],
+  "start_state": "0",
+  "accept_states": ["1"],
+  "transitions": {"0": {"é": "1"}}
+}`))
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, "1", s.Transitions["0"]['é'])
+		// the raw fields keep the json of the version 1
+		assert.Equal(t, map[string]map[string]string{"0": {"é": "1"}}, s.RawTransitions)
+		b, err := json.Marshal(s)
+		if !assert.Nil(t, err) {
+			return
+		}
+		p, err := roughfa.NewDFAMachineShellFromJSON(b)
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, s.Transitions, p.Transitions)
+	})
+	t.Run("version 1 backspace", func(t *testing.T) {
+		s, err := roughfa.NewDFAMachineShellFromJSON([]byte(`{
+  "states": ["0", "1"],
+  "start_state": "0",
+  "accept_states": ["1"],
+  "transitions": {"0": {"\b": "1"}}
+}`))
+		if !assert.Nil(t, err) {
+			return
+		}
+		m, err := s.ToMachine()
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.True(t, m.Accepts("\b"))
+		assert.False(t, m.Accepts("z"))
+	})
+	t.Run("conflicting labels", func(t *testing.T) {
+		_, err := roughfa.NewDFAMachineShellFromJSON([]byte(`{
+  "version": 2,
+  "states": ["0", "1"],
+  "start_state": "0",
+  "accept_states": ["1"],
+  "transitions": {"0": {"on": {"a-c": "1", "b": "0"}}}
+}`))
+		assert.True(t, errors.Is(err, roughfa.ErrCannotUnmarshalMachine), "%v", err)
+	})
+}
+
+func TestDFAMachineShellToJSON(t *testing.T) {
+	m, err := roughfa.NewDFAMachineBuilder().
+		States([]string{"0", "1"}).
+		StartState("0").
+		AcceptStates([]string{"1"}).
+		Transitions(map[string]map[rune]string{
+			"0": {
+				'a':               "1",
+				'b':               "1",
+				'c':               "1",
+				'e':               "0",
+				'-':               "1",
+				'\a':              "1",
+				'é':               "0",
+				roughfa.Otherwise: "0",
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	b, err := m.ToShell().ToJSON()
+	if !assert.Nil(t, err) {
+		return
+	}
+	j := string(b)
+	for _, x := range []string{
+		`"version":2`,
+		`"a-c":"1"`,
+		`"\\-":"1"`,
+		`"\\u0007":"1"`,
+		`"é":"0"`,
+		`"otherwise":"0"`,
+	} {
+		assert.True(t, strings.Contains(j, x), "%s in %s", x, j)
+	}
+	s, err := roughfa.NewDFAMachineShellFromJSON(b)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, m.ToShell().Transitions, s.Transitions)
+}
+
+func TestNFAMachineShellJSONRoundTrip(t *testing.T) {
+	// the chars have epsilon, otherwise and a range wider than the limit
+	chars := []rune{'a', roughfa.Epsilon, roughfa.Otherwise}
+	for c := rune(0x4E00); c < 0x4E00+roughfa.JSONLabelRangeLimit+10; c++ {
+		chars = append(chars, c)
+	}
+	m, err := roughfa.NewNFAMachineBuilder().
+		States([]string{"0", "1", "2"}).
+		Chars(chars).
+		StartStates([]string{"0"}).
+		AcceptStates([]string{"2"}).
+		Transitions(map[string]map[rune][]string{
+			"0": {
+				roughfa.Epsilon: {"1"},
+			},
+			"1": {
+				'a':               {"1"},
+				roughfa.Otherwise: {"2"},
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	b, err := m.ToShell().ToJSON()
+	if !assert.Nil(t, err) {
+		return
+	}
+	s, err := roughfa.NewNFAMachineShellFromJSON(b)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sorted := func(cs []rune) []rune {
+		sort.Slice(cs, func(i, j int) bool { return cs[i] < cs[j] })
+		return cs
+	}
+	assert.Equal(t, sorted(m.ToShell().Chars), sorted(s.Chars))
+	restored, err := s.ToMachine()
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, w := range []string{"", "a", "aa", "一", "a一", "一a", "x"} {
+		assert.Equal(t, m.Accepts(w), restored.Accepts(w), "%q", w)
+	}
+	assert.True(t, restored.Accepts("aa一"))
+}
+
+func TestDFAMachineShellJSONRoundTrip(t *testing.T) {
+	// the greek alphabet has epsilon as an ordinary character
+	var chars []rune
+	ts := map[string]map[rune]string{"0": {}, "1": {}}
+	for c := 'α'; c <= 'ω'; c++ {
+		chars = append(chars, c)
+		ts["0"][c] = "1"
+		ts["1"][c] = "0"
+	}
+	m, err := roughfa.NewDFAMachineBuilder().
+		States([]string{"0", "1"}).
+		Chars(chars).
+		StartState("0").
+		AcceptStates([]string{"1"}).
+		Transitions(ts).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	b, err := m.ToShell().ToJSON()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, strings.Contains(string(b), `"α-ω":"1"`), "%s", b)
+	s, err := roughfa.NewDFAMachineShellFromJSON(b)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, m.ToShell().Transitions, s.Transitions)
+	restored, err := s.ToMachine()
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, w := range []string{"", "α", "ε", "αε", "ω", "a"} {
+		assert.Equal(t, m.Accepts(w), restored.Accepts(w), "%q", w)
+	}
+	assert.True(t, restored.Accepts("ε"))
+}
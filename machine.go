@@ -0,0 +1,157 @@
+package roughfa
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+
+	"github.com/berquerant/roughfa/internal/dot"
+)
+
+type (
+	// Machine is the common interface of DFAMachine and NFAMachine.
+	// Machine is not safe for concurrent use, see NewDefinition to share a machine by goroutines.
+	Machine interface {
+		// Configuration returns the current states.
+		// The configuration of a dfa is the current state.
+		Configuration() []string
+		// Put inputs a character.
+		// Returns an error if invalid input or no transitions.
+		Put(x rune) error
+		// IsAccepted returns true if the current states are acceptable.
+		// The states reachable by epsilon transitions are also considered.
+		IsAccepted() bool
+		// Reset resets the current states to the start states.
+		Reset()
+		// Accepts returns true if this accepts the input.
+		// Accepts runs from the start without changing the current states.
+		Accepts(input string) bool
+		// Run reads the input from the start without changing the current states, and records the trace.
+		// Returns the trace until the error if invalid input or no transitions.
+		Run(input string) (*Trace, error)
+		// Feed reads the input from r and puts the characters into this until the end of the input,
+		// the cancellation of ctx or the condition of stop.
+		// Feed starts from the current states and changes the current states like Put.
+		// Returns a FeedError that has the offset of the failing character if invalid input or no transitions
+		// without DeadFeedStop, or the offset of the next character if ctx is done or r fails.
+		Feed(ctx context.Context, r io.RuneReader, stop FeedStop) (*FeedResult, error)
+		// ToDot generates Dot.
+		ToDot() (dot.Dot, error)
+		// IsDFA returns true if this is a dfa.
+		IsDFA() bool
+		// ToNFA creates a NFAMachine that has the same transitions and current states.
+		ToNFA() NFAMachine
+		// IsEmpty returns true if this accepts no words.
+		// Otherwise returns false and a shortest accepted word.
+		IsEmpty() (bool, string)
+		// IsUniversal returns true if this accepts all words over the chars.
+		// If the chars are universe, the chars of the transitions are used.
+		// Otherwise returns false and a shortest rejected word.
+		IsUniversal() (bool, string)
+		// IsFinite returns true if this accepts finitely many words.
+		// Otherwise returns false and a witness that has a cycle.
+		IsFinite() (bool, *PumpingWitness)
+		// LanguageSize returns the number of the accepted words.
+		// Returns ErrInfiniteLanguage if this accepts infinitely many words.
+		LanguageSize() (*big.Int, error)
+		// CountWords returns the number of the accepted words of length n.
+		CountWords(n int) *big.Int
+		// CountWordsUpTo returns the number of the accepted words whose length is not greater than n.
+		CountWordsUpTo(n int) *big.Int
+	}
+
+	// MachineShell is a serializable form of Machine.
+	// Either DFA or NFA is not nil.
+	MachineShell struct {
+		DFA *DFAMachineShell
+		NFA *NFAMachineShell
+	}
+
+	machineShellHeader struct {
+		StartState  *json.RawMessage `json:"start_state"`
+		StartStates *json.RawMessage `json:"start_states"`
+	}
+)
+
+func (s dfaMachine) Configuration() []string { return []string{s.currentState} }
+func (dfaMachine) IsDFA() bool               { return true }
+func (s dfaMachine) ToNFA() NFAMachine {
+	m := FromDFA(&s)
+	// the current state is in the states
+	_ = m.SetStates([]string{s.currentState})
+	return m
+}
+
+func (s nfaMachine) Configuration() []string { return s.currentStates.Unwrap() }
+func (s nfaMachine) ToNFA() NFAMachine {
+	s.currentStates = s.currentStates.Clone()
+	return &s
+}
+
+// ToMachineShell generates MachineShell of the machine.
+func ToMachineShell(m Machine) *MachineShell {
+	switch m := m.(type) {
+	case DFAMachine:
+		return &MachineShell{
+			DFA: m.ToShell(),
+		}
+	case NFAMachine:
+		return &MachineShell{
+			NFA: m.ToShell(),
+		}
+	default:
+		return &MachineShell{
+			NFA: m.ToNFA().ToShell(),
+		}
+	}
+}
+
+// NewMachineShellFromJSON creates a MachineShell from the json of DFAMachineShell or NFAMachineShell of any version.
+// The json that has start_state is of a dfa, and that has start_states is of a nfa.
+func NewMachineShellFromJSON(b []byte) (*MachineShell, error) {
+	var h machineShellHeader
+	if err := json.Unmarshal(b, &h); err != nil {
+		return nil, err
+	}
+	switch {
+	case h.StartState != nil && h.StartStates == nil:
+		s, err := NewDFAMachineShellFromJSON(b)
+		if err != nil {
+			return nil, err
+		}
+		return &MachineShell{
+			DFA: s,
+		}, nil
+	case h.StartStates != nil && h.StartState == nil:
+		s, err := NewNFAMachineShellFromJSON(b)
+		if err != nil {
+			return nil, err
+		}
+		return &MachineShell{
+			NFA: s,
+		}, nil
+	default:
+		return nil, ErrCannotUnmarshalMachine
+	}
+}
+
+func (s MachineShell) ToJSON() ([]byte, error) {
+	if s.DFA != nil {
+		return s.DFA.ToJSON()
+	}
+	if s.NFA != nil {
+		return s.NFA.ToJSON()
+	}
+	return nil, ErrCannotUnmarshalMachine
+}
+
+func (s MachineShell) ToMachine() (Machine, error) {
+	if s.DFA != nil {
+		return s.DFA.ToMachine()
+	}
+	if s.NFA != nil {
+		return s.NFA.ToMachine()
+	}
+	return nil, ErrCannotUnmarshalMachine
+}
@@ -0,0 +1,136 @@
+package roughfa_test
+
+import (
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMachine(t *testing.T) {
+	d, err := roughfa.NewDFAMachineBuilder().
+		States([]string{"even", "odd"}).
+		StartState("even").
+		AcceptStates([]string{"odd"}).
+		Transitions(map[string]map[rune]string{
+			"even": {
+				'0': "even",
+				'1': "odd",
+			},
+			"odd": {
+				'0': "odd",
+				'1': "even",
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	n, err := roughfa.CompileRegexp("0*1(0|10*1)*")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	for _, tc := range []*struct {
+		title string
+		m     roughfa.Machine
+		isDFA bool
+	}{
+		{
+			title: "dfa",
+			m:     d,
+			isDFA: true,
+		},
+		{
+			title: "nfa",
+			m:     n,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			m := tc.m
+			assert.Equal(t, tc.isDFA, m.IsDFA())
+			for _, c := range "101" {
+				assert.Nil(t, m.Put(c))
+			}
+			assert.False(t, m.IsAccepted())
+			assert.True(t, m.Accepts("0100"))
+			empty, w := m.IsEmpty()
+			assert.False(t, empty)
+			assert.Equal(t, "1", w)
+			assert.Equal(t, int64(4), m.CountWords(3).Int64())
+
+			// the current states are kept
+			x := m.ToNFA()
+			assert.ElementsMatch(t, m.Configuration(), x.Configuration())
+			assert.Nil(t, x.Put('1'))
+			assert.True(t, x.IsAccepted())
+			assert.False(t, m.IsAccepted())
+
+			b, err := roughfa.ToMachineShell(m).ToJSON()
+			if !assert.Nil(t, err) {
+				return
+			}
+			s, err := roughfa.NewMachineShellFromJSON(b)
+			if !assert.Nil(t, err) {
+				return
+			}
+			assert.Equal(t, tc.isDFA, s.DFA != nil)
+			assert.Equal(t, !tc.isDFA, s.NFA != nil)
+			restored, err := s.ToMachine()
+			if !assert.Nil(t, err) {
+				return
+			}
+			assert.ElementsMatch(t, m.Configuration(), restored.Configuration())
+			m.Reset()
+			assert.True(t, m.Accepts("1"))
+		})
+	}
+}
+
+func TestNewMachineShellFromJSON(t *testing.T) {
+	for _, tc := range []*struct {
+		title string
+		json  string
+		isDFA bool
+		err   error
+	}{
+		{
+			title: "dfa version 1",
+			json:  `{"states":["0"],"start_state":"0","accept_states":["0"],"transitions":{"0":{"a":"0"}}}`,
+			isDFA: true,
+		},
+		{
+			title: "nfa version 2",
+			json:  `{"version":2,"states":["0"],"start_states":["0"],"accept_states":["0"],"transitions":{"0":{"on":{"a":["0"]}}}}`,
+		},
+		{
+			title: "unknown",
+			json:  `{"states":["0"],"accept_states":["0"]}`,
+			err:   roughfa.ErrCannotUnmarshalMachine,
+		},
+		{
+			title: "ambiguous",
+			json:  `{"states":["0"],"start_state":"0","start_states":["0"],"accept_states":["0"]}`,
+			err:   roughfa.ErrCannotUnmarshalMachine,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			s, err := roughfa.NewMachineShellFromJSON([]byte(tc.json))
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+			if !assert.Nil(t, err) {
+				return
+			}
+			assert.Equal(t, tc.isDFA, s.DFA != nil)
+			m, err := s.ToMachine()
+			if !assert.Nil(t, err) {
+				return
+			}
+			assert.True(t, m.Accepts("aa"))
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package roughfa
+
+import (
+	"unicode/utf8"
+
+	"github.com/berquerant/roughfa/internal/set"
+)
+
+// MatchSemantics is a rule to choose a match among the matches starting at the same position.
+type MatchSemantics int
+
+const (
+	// LeftmostLongestMatchSemantics chooses the longest match.
+	LeftmostLongestMatchSemantics MatchSemantics = iota
+	// LeftmostShortestMatchSemantics chooses the shortest match.
+	LeftmostShortestMatchSemantics
+)
+
+type (
+	// Match is a substring of the text accepted by the machine.
+	Match struct {
+		// Start is the byte offset of the start of the match.
+		Start int
+		// End is the byte offset of the end of the match, exclusive.
+		End int
+	}
+
+	// Matcher finds the substrings accepted by a machine like regexp.
+	// The matches may be empty.
+	Matcher interface {
+		// FindFirst returns the leftmost match.
+		// Returns nil if no matches.
+		FindFirst(text string) *Match
+		// FindAll returns the successive non-overlapping matches.
+		// If n >= 0, returns at most n matches.
+		// Empty matches abutting a preceding match are ignored.
+		FindAll(text string, n int) []*Match
+		// FindLongest returns the longest match, the leftmost one if there are some longest matches.
+		// FindLongest ignores the semantics.
+		// Returns nil if no matches.
+		FindLongest(text string) *Match
+	}
+
+	matcher struct {
+		// m is a dfa without the states that cannot reach an accept state.
+		m         *nfaMachine
+		chars     set.RuneSet
+		semantics MatchSemantics
+	}
+)
+
+// NewMatcher creates a new Matcher.
+// The machine is determinized unless it is a dfa.
+// A character that is not in the chars of the machine matches nothing.
+func NewMatcher(m NFAMachine, semantics MatchSemantics) Matcher {
+	x := asNFAMachine(m)
+	d := x.determinize()
+	return &matcher{
+		m:         d.restrict(d.coReachableStates()),
+		chars:     x.chars,
+		semantics: semantics,
+	}
+}
+
+// NewDFAMatcher creates a new Matcher from DFAMachine.
+func NewDFAMatcher(m DFAMachine, semantics MatchSemantics) Matcher {
+	return NewMatcher(FromDFA(m), semantics)
+}
+
+func (s matcher) FindFirst(text string) *Match {
+	for i := 0; i <= len(text); {
+		if end, ok := s.matchAt(text, i, s.semantics); ok {
+			return &Match{
+				Start: i,
+				End:   end,
+			}
+		}
+		i += s.runeLen(text, i)
+	}
+	return nil
+}
+
+func (s matcher) FindAll(text string, n int) []*Match {
+	var (
+		matches []*Match
+		prevEnd = -1
+	)
+	for i := 0; i <= len(text) && (n < 0 || len(matches) < n); {
+		end, ok := s.matchAt(text, i, s.semantics)
+		if !ok || end == i && i == prevEnd {
+			i += s.runeLen(text, i)
+			continue
+		}
+		matches = append(matches, &Match{
+			Start: i,
+			End:   end,
+		})
+		prevEnd = end
+		if end > i {
+			i = end
+			continue
+		}
+		i += s.runeLen(text, i)
+	}
+	return matches
+}
+
+func (s matcher) FindLongest(text string) *Match {
+	var longest *Match
+	for i := 0; i <= len(text); {
+		end, ok := s.matchAt(text, i, LeftmostLongestMatchSemantics)
+		if ok && (longest == nil || end-i > longest.End-longest.Start) {
+			longest = &Match{
+				Start: i,
+				End:   end,
+			}
+		}
+		i += s.runeLen(text, i)
+	}
+	return longest
+}
+
+// matchAt returns the end of the match starting at the start.
+// Returns false if no matches.
+func (s matcher) matchAt(text string, start int, semantics MatchSemantics) (int, bool) {
+	if s.m.startStates.Len() == 0 {
+		// accepts no words
+		return 0, false
+	}
+	var (
+		state    = s.m.startStates.Unwrap()[0]
+		end      int
+		accepted bool
+	)
+	for i := start; ; {
+		if s.m.acceptStates.In(state) {
+			end, accepted = i, true
+			if semantics == LeftmostShortestMatchSemantics {
+				return end, true
+			}
+		}
+		if i >= len(text) {
+			return end, accepted
+		}
+		c, size := utf8.DecodeRuneInString(text[i:])
+		if s.chars.Len() > 0 && !s.chars.In(c) {
+			return end, accepted
+		}
+		next, ok := s.m.dfaNext(state, c)
+		if !ok {
+			return end, accepted
+		}
+		state = next
+		i += size
+	}
+}
+
+// runeLen returns the byte length of the rune at i, or 1 if i is the end of the text.
+func (matcher) runeLen(text string, i int) int {
+	if i >= len(text) {
+		return 1
+	}
+	_, size := utf8.DecodeRuneInString(text[i:])
+	return size
+}
@@ -0,0 +1,126 @@
+package roughfa_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+func toMatches(indices [][]int) []*roughfa.Match {
+	var matches []*roughfa.Match
+	for _, x := range indices {
+		matches = append(matches, &roughfa.Match{
+			Start: x[0],
+			End:   x[1],
+		})
+	}
+	return matches
+}
+
+func TestMatcherLongest(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		text    string
+	}{
+		{pattern: "ab*", text: "xabbbyaab"},
+		{pattern: "a*", text: "baaacあa"},
+		{pattern: "(ab|a)(bc|c)?", text: "abcabc xabc"},
+		{pattern: "x", text: "abc"},
+		{pattern: "あい|い", text: "あいういあい"},
+	} {
+		tc := tc
+		t.Run(tc.pattern, func(t *testing.T) {
+			m, err := roughfa.CompileRegexp(tc.pattern)
+			if !assert.Nil(t, err) {
+				return
+			}
+			r := regexp.MustCompile(tc.pattern)
+			r.Longest()
+			matcher := roughfa.NewMatcher(m, roughfa.LeftmostLongestMatchSemantics)
+
+			want := toMatches(r.FindAllStringIndex(tc.text, -1))
+			assert.Equal(t, want, matcher.FindAll(tc.text, -1), "all")
+			if len(want) > 0 {
+				assert.Equal(t, want[:1], matcher.FindAll(tc.text, 1), "all 1")
+				assert.Equal(t, want[0], matcher.FindFirst(tc.text), "first")
+			} else {
+				assert.Nil(t, matcher.FindFirst(tc.text), "first")
+			}
+		})
+	}
+}
+
+type matcherTestcase struct {
+	name        string
+	pattern     string
+	text        string
+	semantics   roughfa.MatchSemantics
+	wantFirst   *roughfa.Match
+	wantAll     []*roughfa.Match
+	wantLongest *roughfa.Match
+}
+
+func (s matcherTestcase) test(t *testing.T) {
+	m, err := roughfa.CompileRegexp(s.pattern)
+	if !assert.Nil(t, err) {
+		return
+	}
+	d, err := m.ApplyEpsilonExpansion().ApplyPowersetConstruction()
+	if !assert.Nil(t, err) {
+		return
+	}
+	dfa, err := d.ToDFA()
+	if !assert.Nil(t, err) {
+		return
+	}
+	for name, matcher := range map[string]roughfa.Matcher{
+		"nfa": roughfa.NewMatcher(m, s.semantics),
+		"dfa": roughfa.NewDFAMatcher(dfa, s.semantics),
+	} {
+		assert.Equal(t, s.wantFirst, matcher.FindFirst(s.text), "%s first", name)
+		assert.Equal(t, s.wantAll, matcher.FindAll(s.text, -1), "%s all", name)
+		assert.Equal(t, s.wantLongest, matcher.FindLongest(s.text), "%s longest", name)
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	for _, tc := range []*matcherTestcase{
+		{
+			name:        "shortest",
+			pattern:     "ab+",
+			text:        "xabbyabbb",
+			semantics:   roughfa.LeftmostShortestMatchSemantics,
+			wantFirst:   &roughfa.Match{Start: 1, End: 3},
+			wantAll:     []*roughfa.Match{{Start: 1, End: 3}, {Start: 5, End: 7}},
+			wantLongest: &roughfa.Match{Start: 5, End: 9},
+		},
+		{
+			name:        "shortest empty",
+			pattern:     "a*",
+			text:        "aa",
+			semantics:   roughfa.LeftmostShortestMatchSemantics,
+			wantFirst:   &roughfa.Match{Start: 0, End: 0},
+			wantAll:     []*roughfa.Match{{Start: 0, End: 0}, {Start: 1, End: 1}, {Start: 2, End: 2}},
+			wantLongest: &roughfa.Match{Start: 0, End: 2},
+		},
+		{
+			name:        "longest",
+			pattern:     "b|abc|ab",
+			text:        "xbabcab",
+			semantics:   roughfa.LeftmostLongestMatchSemantics,
+			wantFirst:   &roughfa.Match{Start: 1, End: 2},
+			wantAll:     []*roughfa.Match{{Start: 1, End: 2}, {Start: 2, End: 5}, {Start: 5, End: 7}},
+			wantLongest: &roughfa.Match{Start: 2, End: 5},
+		},
+		{
+			name:      "no matches",
+			pattern:   "ab",
+			text:      "ba",
+			semantics: roughfa.LeftmostLongestMatchSemantics,
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
@@ -0,0 +1,258 @@
+package roughfa
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/berquerant/roughfa/internal/set"
+)
+
+type (
+	// PumpingWitness is an evidence that a machine accepts infinitely many words.
+	// The machine accepts Prefix, Cycle repeated n times and Suffix for all n >= 0.
+	PumpingWitness struct {
+		Prefix string
+		Cycle  string
+		Suffix string
+	}
+
+	// wordStep is a transition to reach a state, c is Epsilon if the transition is an epsilon transition.
+	wordStep struct {
+		from string
+		c    rune
+	}
+)
+
+func (s nfaMachine) IsEmpty() (bool, string) {
+	s = *s.expandOtherwise()
+	w, ok := s.shortestWord(s.startStates, s.acceptStates)
+	return !ok, w
+}
+
+func (s nfaMachine) IsUniversal() (bool, string) {
+	return s.complement().IsEmpty()
+}
+
+func (s nfaMachine) IsFinite() (bool, *PumpingWitness) {
+	var (
+		m      = s.applyEpsilonExpansion()
+		useful = m.reachableStates().And(m.coReachableStates())
+	)
+	m = m.restrict(useful)
+	state, cycle, ok := m.findCycle()
+	if !ok {
+		return true, nil
+	}
+	var (
+		prefix, _ = m.shortestWord(m.startStates, set.NewStringSet(state))
+		suffix, _ = m.shortestWord(set.NewStringSet(state), m.acceptStates)
+	)
+	return false, &PumpingWitness{
+		Prefix: prefix,
+		Cycle:  cycle,
+		Suffix: suffix,
+	}
+}
+
+func (s nfaMachine) LanguageSize() (*big.Int, error) {
+	if ok, _ := s.IsFinite(); !ok {
+		return nil, ErrInfiniteLanguage
+	}
+	var (
+		d     = s.determinize()
+		d2    = d.restrict(d.coReachableStates())
+		memo  = map[string]*big.Int{}
+		count func(state string) *big.Int
+	)
+	// the useful part of the dfa is acyclic and a path corresponds to a word
+	count = func(state string) *big.Int {
+		if x, ok := memo[state]; ok {
+			return x
+		}
+		n := big.NewInt(0)
+		if d2.acceptStates.In(state) {
+			n.SetInt64(1)
+		}
+		for _, toStates := range d2.transitions[state] {
+			for _, x := range toStates.Unwrap() {
+				n.Add(n, count(x))
+			}
+		}
+		memo[state] = n
+		return n
+	}
+	n := big.NewInt(0)
+	for _, x := range d2.startStates.Unwrap() {
+		n.Add(n, count(x))
+	}
+	return n, nil
+}
+
+func (s nfaMachine) CountWords(n int) *big.Int     { return s.countWords(n, false) }
+func (s nfaMachine) CountWordsUpTo(n int) *big.Int { return s.countWords(n, true) }
+
+// countWords counts the accepted words of length n, or not greater than n if upTo is true,
+// by counting the paths of the determinized machine.
+func (s nfaMachine) countWords(n int, upTo bool) *big.Int {
+	var (
+		d     = s.determinize()
+		d2    = d.restrict(d.reachableStates().And(d.coReachableStates()))
+		total = big.NewInt(0)
+		// paths[q] is the number of the paths from the start state to q of the current length
+		paths = map[string]*big.Int{}
+	)
+	for _, x := range d2.startStates.Unwrap() {
+		paths[x] = big.NewInt(1)
+	}
+	for i := 0; i <= n && len(paths) > 0; i++ {
+		if upTo || i == n {
+			for _, x := range d2.acceptStates.Unwrap() {
+				if p, ok := paths[x]; ok {
+					total.Add(total, p)
+				}
+			}
+		}
+		if i == n {
+			break
+		}
+		next := map[string]*big.Int{}
+		for state, p := range paths {
+			for _, toStates := range d2.transitions[state] {
+				to := toStates.Unwrap()[0]
+				if _, ok := next[to]; !ok {
+					next[to] = big.NewInt(0)
+				}
+				next[to].Add(next[to], p)
+			}
+		}
+		paths = next
+	}
+	return total
+}
+
+// shortestWord returns a shortest word that leads from one of from to one of to.
+// Returns false if no such words.
+func (s nfaMachine) shortestWord(from, to set.StringSet) (string, bool) {
+	var (
+		steps = map[string]*wordStep{}
+		layer = sortedStrings(from)
+	)
+	for _, x := range layer {
+		steps[x] = nil
+	}
+	// add the states reachable by epsilon transitions to the layer
+	closure := func(layer []string) []string {
+		for i := 0; i < len(layer); i++ {
+			toStates, ok := s.transitions[layer[i]][Epsilon]
+			if !ok {
+				continue
+			}
+			for _, x := range sortedStrings(toStates) {
+				if _, ok := steps[x]; ok {
+					continue
+				}
+				steps[x] = &wordStep{from: layer[i], c: Epsilon}
+				layer = append(layer, x)
+			}
+		}
+		return layer
+	}
+	for layer = closure(layer); len(layer) > 0; {
+		for _, x := range layer {
+			if to.In(x) {
+				return s.wordTo(x, steps), true
+			}
+		}
+		var next []string
+		for _, x := range layer {
+			chars := make([]rune, 0, len(s.transitions[x]))
+			for c := range s.transitions[x] {
+				if c != Epsilon {
+					chars = append(chars, c)
+				}
+			}
+			sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+			for _, c := range chars {
+				for _, y := range sortedStrings(s.transitions[x][c]) {
+					if _, ok := steps[y]; ok {
+						continue
+					}
+					steps[y] = &wordStep{from: x, c: c}
+					next = append(next, y)
+				}
+			}
+		}
+		layer = closure(next)
+	}
+	return "", false
+}
+
+// wordTo returns the word that leads to the state by the steps.
+func (nfaMachine) wordTo(state string, steps map[string]*wordStep) string {
+	var w []rune
+	for x := steps[state]; x != nil; x = steps[x.from] {
+		if x.c != Epsilon {
+			w = append(w, x.c)
+		}
+	}
+	for i, j := 0, len(w)-1; i < j; i, j = i+1, j-1 {
+		w[i], w[j] = w[j], w[i]
+	}
+	return string(w)
+}
+
+// findCycle finds a cycle reachable from the start states of the machine without epsilon transitions.
+// Returns a state on the cycle and the word of the cycle from the state.
+func (s nfaMachine) findCycle() (string, string, bool) {
+	const (
+		white = iota
+		gray
+		black
+	)
+	var (
+		color = map[string]int{}
+		stack []string
+		chars []rune
+		visit func(state string) (string, string, bool)
+	)
+	visit = func(state string) (string, string, bool) {
+		color[state] = gray
+		stack = append(stack, state)
+		cs := make([]rune, 0, len(s.transitions[state]))
+		for c := range s.transitions[state] {
+			cs = append(cs, c)
+		}
+		sort.Slice(cs, func(i, j int) bool { return cs[i] < cs[j] })
+		for _, c := range cs {
+			for _, x := range sortedStrings(s.transitions[state][c]) {
+				switch color[x] {
+				case gray:
+					// x is on the stack
+					for i, y := range stack {
+						if y == x {
+							return x, string(append(append([]rune{}, chars[i:]...), c)), true
+						}
+					}
+				case white:
+					chars = append(chars, c)
+					if y, w, ok := visit(x); ok {
+						return y, w, true
+					}
+					chars = chars[:len(chars)-1]
+				}
+			}
+		}
+		color[state] = black
+		stack = stack[:len(stack)-1]
+		return "", "", false
+	}
+	for _, x := range sortedStrings(s.startStates) {
+		if color[x] != white {
+			continue
+		}
+		if state, w, ok := visit(x); ok {
+			return state, w, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,324 @@
+package roughfa_test
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+type nfaAnalysisTestcase struct {
+	name          string
+	pattern       string
+	chars         string
+	wantEmpty     bool
+	wantShortest  string
+	wantUniversal bool
+	wantRejected  string
+	wantFinite    bool
+}
+
+func (s nfaAnalysisTestcase) test(t *testing.T) {
+	m, err := roughfa.CompileRegexp(s.pattern)
+	if !assert.Nil(t, err) {
+		return
+	}
+	r := regexp.MustCompile(`^(?:` + s.pattern + `)$`)
+
+	empty, w := m.IsEmpty()
+	assert.Equal(t, s.wantEmpty, empty, "empty")
+	assert.Equal(t, s.wantShortest, w, "shortest")
+
+	universal, w := m.IsUniversal()
+	assert.Equal(t, s.wantUniversal, universal, "universal")
+	assert.Equal(t, s.wantRejected, w, "rejected")
+
+	finite, p := m.IsFinite()
+	assert.Equal(t, s.wantFinite, finite, "finite")
+	if !finite {
+		t.Logf("pump: %#v", p)
+		for i := 0; i < 4; i++ {
+			x := p.Prefix + strings.Repeat(p.Cycle, i) + p.Suffix
+			assert.True(t, r.MatchString(x), "pump %q", x)
+		}
+		_, err := m.LanguageSize()
+		assert.Equal(t, roughfa.ErrInfiniteLanguage, err)
+		return
+	}
+	n, err := m.LanguageSize()
+	if !assert.Nil(t, err) {
+		return
+	}
+	var want int64
+	for _, x := range allWords(s.chars, 6) {
+		if r.MatchString(x) {
+			want++
+		}
+	}
+	assert.Equal(t, want, n.Int64(), "size")
+}
+
+func TestNFAAnalysis(t *testing.T) {
+	for _, tc := range []*nfaAnalysisTestcase{
+		{
+			name:         "finite",
+			pattern:      "(a|b)(c|d|)",
+			chars:        "abcd",
+			wantShortest: "a",
+			wantRejected: "",
+			wantFinite:   true,
+		},
+		{
+			name:         "empty word",
+			pattern:      "",
+			chars:        "a",
+			wantShortest: "",
+			// no chars in the transitions
+			wantUniversal: true,
+			wantFinite:    true,
+		},
+		{
+			name:         "ambiguous",
+			pattern:      "a|a|ab|(a|)b",
+			chars:        "ab",
+			wantShortest: "a",
+			wantFinite:   true,
+		},
+		{
+			name:         "infinite",
+			pattern:      "ab*c",
+			chars:        "abc",
+			wantShortest: "ac",
+			wantRejected: "",
+		},
+		{
+			name:         "epsilon cycle",
+			pattern:      "(a*)*b",
+			chars:        "ab",
+			wantShortest: "b",
+			wantRejected: "",
+		},
+		{
+			name:          "universal",
+			pattern:       "(a|b)*",
+			chars:         "ab",
+			wantUniversal: true,
+		},
+		{
+			name:         "almost universal",
+			pattern:      "(a|b)*a|b*",
+			chars:        "ab",
+			wantRejected: "ab",
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestNFAAnalysisEmpty(t *testing.T) {
+	m, err := roughfa.NewNFAMachineBuilder().
+		States([]string{"0", "1"}).
+		StartStates([]string{"0"}).
+		AcceptStates([]string{"1"}).
+		Transitions(map[string]map[rune][]string{
+			"0": {
+				'a': {"0"},
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	empty, w := m.IsEmpty()
+	assert.True(t, empty)
+	assert.Equal(t, "", w)
+	finite, _ := m.IsFinite()
+	assert.True(t, finite)
+	n, err := m.LanguageSize()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), n.Int64())
+}
+
+func TestNFAAnalysisEmptyOtherwise(t *testing.T) {
+	newMachine := func(chars string) roughfa.NFAMachine {
+		m, err := roughfa.NewNFAMachineBuilder().
+			States([]string{"0", "1"}).
+			Chars([]rune(chars)).
+			StartStates([]string{"0"}).
+			AcceptStates([]string{"1"}).
+			Transitions(map[string]map[rune][]string{
+				"0": {
+					'a':               {"0"},
+					roughfa.Otherwise: {"1"},
+				},
+			}).
+			Build()
+		if err != nil {
+			panic(err)
+		}
+		return m
+	}
+
+	t.Run("no other chars", func(t *testing.T) {
+		m := newMachine("a")
+		empty, w := m.IsEmpty()
+		assert.True(t, empty)
+		assert.Equal(t, "", w)
+		n, err := m.LanguageSize()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), n.Int64())
+	})
+	t.Run("other chars", func(t *testing.T) {
+		m := newMachine("ab")
+		empty, w := m.IsEmpty()
+		assert.False(t, empty)
+		assert.Equal(t, "b", w)
+		assert.True(t, m.Accepts(w))
+	})
+	t.Run("dfa", func(t *testing.T) {
+		d, err := roughfa.NewDFAMachineBuilder().
+			States([]string{"0", "1"}).
+			Chars([]rune("a")).
+			StartState("0").
+			AcceptStates([]string{"1"}).
+			Transitions(map[string]map[rune]string{
+				"0": {
+					'a':               "0",
+					roughfa.Otherwise: "1",
+				},
+			}).
+			Build()
+		if !assert.Nil(t, err) {
+			return
+		}
+		empty, w := d.IsEmpty()
+		assert.True(t, empty)
+		assert.Equal(t, "", w)
+	})
+}
+
+func TestNFAAnalysisDeclaredEpsilon(t *testing.T) {
+	// accepts a*
+	m, err := roughfa.NewNFAMachineBuilder().
+		States([]string{"s", "f"}).
+		Chars([]rune{'a', roughfa.Epsilon}).
+		StartStates([]string{"s"}).
+		AcceptStates([]string{"f"}).
+		Transitions(map[string]map[rune][]string{
+			"s": {
+				roughfa.Epsilon: {"f"},
+			},
+			"f": {
+				'a': {"f"},
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	universal, w := m.IsUniversal()
+	assert.True(t, universal)
+	assert.Equal(t, "", w)
+	var (
+		complement = m.Complement()
+		complete   = m.Complete()
+	)
+	for _, x := range allWords("a", 3) {
+		assert.True(t, m.Accepts(x), "%q", x)
+		assert.False(t, complement.Accepts(x), "complement %q", x)
+		assert.True(t, complete.Accepts(x), "complete %q", x)
+	}
+	_, err = complete.ToShell().ToMachine()
+	assert.Nil(t, err)
+}
+
+func TestDFAAnalysis(t *testing.T) {
+	m, err := roughfa.NewDFAMachineBuilder().
+		States([]string{"0", "1", "2"}).
+		Chars([]rune{'0', '1'}).
+		StartState("0").
+		AcceptStates([]string{"2"}).
+		Transitions(map[string]map[rune]string{
+			"0": {
+				'1': "1",
+			},
+			"1": {
+				'0': "2",
+				'1': "2",
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	empty, w := m.IsEmpty()
+	assert.False(t, empty)
+	assert.Equal(t, "10", w)
+	universal, w := m.IsUniversal()
+	assert.False(t, universal)
+	assert.Equal(t, "", w)
+	finite, _ := m.IsFinite()
+	assert.True(t, finite)
+	n, err := m.LanguageSize()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), n.Int64())
+}
+
+func TestCountWords(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		chars   string
+	}{
+		{pattern: "(a|b)*abb", chars: "ab"},
+		{pattern: "(ab|a)(bc|c)?", chars: "abc"},
+		{pattern: "a*|b*", chars: "ab"},
+		{pattern: "ab", chars: "ab"},
+		{pattern: "", chars: "a"},
+	} {
+		tc := tc
+		t.Run(tc.pattern, func(t *testing.T) {
+			m, err := roughfa.CompileRegexp(tc.pattern)
+			if !assert.Nil(t, err) {
+				return
+			}
+			d, err := m.ApplyEpsilonExpansion().ApplyPowersetConstruction()
+			if !assert.Nil(t, err) {
+				return
+			}
+			dfa, err := d.ToDFA()
+			if !assert.Nil(t, err) {
+				return
+			}
+			r := regexp.MustCompile(`^(?:` + tc.pattern + `)$`)
+			counts := make([]int64, 6)
+			for _, w := range allWords(tc.chars, 5) {
+				if r.MatchString(w) {
+					counts[len(w)]++
+				}
+			}
+			var upTo int64
+			for n, want := range counts {
+				upTo += want
+				assert.Equal(t, want, m.CountWords(n).Int64(), "nfa %d", n)
+				assert.Equal(t, want, dfa.CountWords(n).Int64(), "dfa %d", n)
+				assert.Equal(t, upTo, m.CountWordsUpTo(n).Int64(), "nfa up to %d", n)
+				assert.Equal(t, upTo, dfa.CountWordsUpTo(n).Int64(), "dfa up to %d", n)
+			}
+			assert.Equal(t, int64(0), m.CountWords(-1).Int64())
+		})
+	}
+}
+
+func TestCountWordsLarge(t *testing.T) {
+	m, err := roughfa.CompileRegexp("(a|b)*")
+	if !assert.Nil(t, err) {
+		return
+	}
+	want := new(big.Int).Lsh(big.NewInt(1), 100)
+	assert.Equal(t, 0, want.Cmp(m.CountWords(100)))
+	want.Lsh(want, 1).Sub(want, big.NewInt(1))
+	assert.Equal(t, 0, want.Cmp(m.CountWordsUpTo(100)))
+}
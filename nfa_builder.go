@@ -19,6 +19,7 @@ type (
 		// Must be a subset of States.
 		AcceptStates(acceptStates []string) NFAMachineBuilder
 		// Transitions configures the transition function of the machine.
+		// Epsilon is for the epsilon transitions and Otherwise is for the otherwise transitions.
 		// Required.
 		Transitions(transitions map[string]map[rune][]string) NFAMachineBuilder
 		// Build creates a new NFAMachine.
@@ -87,6 +88,7 @@ func (s nfaMachineBuilder) Build() (NFAMachine, error) {
 		}
 	}
 	chars := set.NewRuneSet(s.chars...)
+	tChars.Del(Otherwise)
 	if !states.In(tStates.Unwrap()...) || chars.Len() > 0 && !chars.In(tChars.Unwrap()...) {
 		return nil, ErrInvalidTransitions
 	}
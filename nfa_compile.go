@@ -0,0 +1,196 @@
+package roughfa
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/berquerant/roughfa/internal/set"
+)
+
+type (
+	// CompiledNFA is a nfa compiled for the simulation by the sets of the numbered states.
+	// The epsilon closures are computed in advance,
+	// so the cost of reading a character is proportional to the transitions from the current states.
+	// The characters are compressed into the classes like CompiledDFA.
+	// CompiledNFA is immutable and safe for concurrent use by multiple goroutines.
+	CompiledNFA interface {
+		// Match returns true if the nfa accepts the utf-8 encoded input.
+		// An invalid utf-8 sequence is read as utf8.RuneError like the range over the string.
+		Match(input []byte) bool
+		// MatchString is Match for the string.
+		MatchString(input string) bool
+		// NumStates returns the number of the states.
+		NumStates() int
+		// NumClasses returns the number of the classes of the characters.
+		NumClasses() int
+	}
+
+	compiledNFA struct {
+		classes *charClasses
+		// next are the epsilon closures of the next states by the state * the number of the classes + the class.
+		next [][]int32
+		// start is the epsilon closure of the start states.
+		start  []int32
+		accept []bool
+		// runs are *nfaRun to reuse.
+		runs sync.Pool
+	}
+
+	// nfaRun is the current states of a simulation.
+	nfaRun struct {
+		states []int32
+		// in is the bitset of the states.
+		in []uint64
+	}
+)
+
+func (s nfaMachine) Compile() CompiledNFA {
+	ids, names := numberStates(s.startStates.Unwrap(), s.states)
+	closure := func(states set.StringSet) []int32 {
+		xs := []int32{}
+		for _, state := range s.epsilonClosure(states).Unwrap() {
+			xs = append(xs, ids[state])
+		}
+		sort.Slice(xs, func(i, j int) bool { return xs[i] < xs[j] })
+		return xs
+	}
+	column := func(c rune) [][]int32 {
+		xs := make([][]int32, len(names))
+		for i, state := range names {
+			xs[i] = []int32{}
+			if toStates, ok := s.move(state, c); ok {
+				xs[i] = closure(toStates)
+			}
+		}
+		return xs
+	}
+	other := make([][]int32, len(names))
+	for i := range other {
+		other[i] = []int32{}
+	}
+	if s.chars.Len() == 0 {
+		other = column(Otherwise)
+	}
+	alphabet := s.alphabet()
+	if s.chars.Len() > 0 && s.chars.In(Otherwise) {
+		// the input of otherwise in the chars takes the otherwise transitions
+		alphabet.Add(Otherwise)
+	}
+	var (
+		classes = newCharClasses(alphabet, fmt.Sprint(other), func(c rune) string { return fmt.Sprint(column(c)) })
+		n       = classes.len()
+		r       = &compiledNFA{
+			classes: classes,
+			next:    make([][]int32, len(names)*n),
+			start:   closure(s.startStates),
+			accept:  make([]bool, len(names)),
+		}
+	)
+	for class, c := range classes.reps {
+		xs := other
+		if class > 0 {
+			xs = column(c)
+		}
+		for i := range names {
+			r.next[i*n+class] = xs[i]
+		}
+	}
+	for i, state := range names {
+		r.accept[i] = s.acceptStates.In(state)
+	}
+	r.runs.New = func() any {
+		return &nfaRun{
+			in: make([]uint64, (len(names)+63)/64),
+		}
+	}
+	return r
+}
+
+func (s *compiledNFA) NumStates() int  { return len(s.accept) }
+func (s *compiledNFA) NumClasses() int { return s.classes.len() }
+
+func (s *compiledNFA) Match(input []byte) bool {
+	current, next := s.newRun(), s.newRun()
+	defer s.runs.Put(current)
+	defer s.runs.Put(next)
+	current.reset(s.start)
+	for i := 0; i < len(input); {
+		c, size := rune(input[i]), 1
+		if c >= utf8.RuneSelf {
+			c, size = utf8.DecodeRune(input[i:])
+		}
+		if !s.step(current, next, c) {
+			return false
+		}
+		current, next = next, current
+		i += size
+	}
+	return s.isAccepted(current)
+}
+
+func (s *compiledNFA) MatchString(input string) bool {
+	current, next := s.newRun(), s.newRun()
+	defer s.runs.Put(current)
+	defer s.runs.Put(next)
+	current.reset(s.start)
+	for i := 0; i < len(input); {
+		c, size := rune(input[i]), 1
+		if c >= utf8.RuneSelf {
+			c, size = utf8.DecodeRuneInString(input[i:])
+		}
+		if !s.step(current, next, c) {
+			return false
+		}
+		current, next = next, current
+		i += size
+	}
+	return s.isAccepted(current)
+}
+
+func (s *compiledNFA) newRun() *nfaRun { return s.runs.Get().(*nfaRun) }
+
+// step sets the states after reading c from the current states to next.
+// Returns false if next is empty.
+func (s *compiledNFA) step(current, next *nfaRun, c rune) bool {
+	var (
+		n     = s.classes.len()
+		class = int(s.classes.classOf(c))
+	)
+	next.reset(nil)
+	for _, q := range current.states {
+		for _, p := range s.next[int(q)*n+class] {
+			next.add(p)
+		}
+	}
+	return len(next.states) > 0
+}
+
+func (s *compiledNFA) isAccepted(r *nfaRun) bool {
+	for _, q := range r.states {
+		if s.accept[q] {
+			return true
+		}
+	}
+	return false
+}
+
+// reset sets the states, clearing only the bits of the current states.
+func (s *nfaRun) reset(states []int32) {
+	for _, q := range s.states {
+		s.in[q/64] &^= 1 << (q % 64)
+	}
+	s.states = s.states[:0]
+	for _, q := range states {
+		s.add(q)
+	}
+}
+
+func (s *nfaRun) add(q int32) {
+	if s.in[q/64]&(1<<(q%64)) != 0 {
+		return
+	}
+	s.in[q/64] |= 1 << (q % 64)
+	s.states = append(s.states, q)
+}
@@ -0,0 +1,106 @@
+package roughfa_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompiledNFA(t *testing.T) {
+	comment, err := newCommentMachine([]rune{'/', '\n', 'x', roughfa.Otherwise})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	for _, tc := range []*struct {
+		title   string
+		pattern string
+		m       roughfa.NFAMachine
+		chars   string
+	}{
+		{
+			title:   "regexp",
+			pattern: "(a|b)*a(a|b)(a|b)",
+			chars:   "abc",
+		},
+		{
+			title:   "epsilon",
+			pattern: "(a*b*)*c?|(ab)+",
+			chars:   "abc",
+		},
+		{
+			title:   "non-ascii",
+			pattern: "(あ|い)*う|x",
+			chars:   "あいうxえ",
+		},
+		{
+			title: "otherwise",
+			m:     roughfa.FromDFA(comment),
+			chars: "/\nxy",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			m := tc.m
+			if m == nil {
+				var err error
+				if m, err = roughfa.CompileRegexp(tc.pattern); !assert.Nil(t, err) {
+					return
+				}
+			}
+			var (
+				c     = m.Compile()
+				words = allWords(tc.chars, 4)
+				wg    sync.WaitGroup
+			)
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for _, w := range words {
+						want := m.Accepts(w)
+						assert.Equal(t, want, c.MatchString(w), "%q", w)
+						assert.Equal(t, want, c.Match([]byte(w)), "bytes %q", w)
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func BenchmarkNFAMatch(b *testing.B) {
+	m, err := roughfa.CompileRegexp("(a|b|あ)*a(a|b|あ)(a|b|あ)(a|b|あ)")
+	if err != nil {
+		b.Fatal(err)
+	}
+	var (
+		input = strings.Repeat("abあb", 64) + "aaab"
+		c     = m.Compile()
+	)
+	b.Run("map", func(b *testing.B) {
+		b.SetBytes(int64(len(input)))
+		for i := 0; i < b.N; i++ {
+			m.Reset()
+			for _, x := range input {
+				if err := m.Put(x); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if !m.IsAccepted() {
+				b.Fatal("not accepted")
+			}
+		}
+	})
+	b.Run("compiled", func(b *testing.B) {
+		b.SetBytes(int64(len(input)))
+		for i := 0; i < b.N; i++ {
+			if !c.MatchString(input) {
+				b.Fatal("not accepted")
+			}
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package roughfa
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/berquerant/roughfa/internal/dot"
+	"github.com/berquerant/roughfa/internal/set"
+)
+
+// PowersetNaming is a strategy to name the states created by the powerset construction.
+type PowersetNaming int
+
+const (
+	// NumberPowersetNaming names the states by sequential numbers in order of discovery, 0 is the start state.
+	NumberPowersetNaming PowersetNaming = iota
+	// SubsetPowersetNaming names the states by the sorted original states like {q1,q3}.
+	SubsetPowersetNaming
+	// HashPowersetNaming names the states by the hexadecimal hash of the original states.
+	HashPowersetNaming
+)
+
+func (s PowersetNaming) String() string {
+	switch s {
+	case NumberPowersetNaming:
+		return "number"
+	case SubsetPowersetNaming:
+		return "subset"
+	case HashPowersetNaming:
+		return "hash"
+	default:
+		return "unknown"
+	}
+}
+
+type (
+	// DeterminizeResult is a dfa created by the powerset construction and the provenance of the states.
+	DeterminizeResult struct {
+		Machine DFAMachine
+		// States is the map from the state of Machine to the sorted states of the original machine.
+		// The dead state that is added so that the otherwise transitions are not taken by the other characters
+		// is mapped to the empty states.
+		States map[string][]string
+	}
+
+	powersetNamer struct {
+		naming PowersetNaming
+		used   set.StringSet
+	}
+)
+
+// ToDot generates Dot whose states are labeled by the original states.
+func (s DeterminizeResult) ToDot() (dot.Dot, error) {
+	var (
+		m      = s.Machine.ToShell()
+		labels = make(map[string]string, len(s.States))
+	)
+	for k, v := range s.States {
+		labels[k] = subsetName(v)
+	}
+	return dot.NewDFADotBuilder().
+		StartState(m.StartState).
+		States(m.States).
+		AcceptStates(m.AcceptStates).
+		Transitions(m.Transitions).
+		Labels(labels).
+		Build()
+}
+
+func (s nfaMachine) Determinize(naming PowersetNaming) *DeterminizeResult {
+	m, subsets := s.applyEpsilonExpansion().namedPowersetConstruction(naming)
+	transitions := make(map[string]map[rune]string, len(m.transitions))
+	for k, v := range m.transitions {
+		transitions[k] = make(map[rune]string, len(v))
+		for c, toStates := range v {
+			transitions[k][c] = toStates.Unwrap()[0]
+		}
+	}
+	start := m.startStates.Unwrap()[0]
+	return &DeterminizeResult{
+		Machine: &dfaMachine{
+			states:       m.states,
+			chars:        m.chars,
+			startState:   start,
+			acceptStates: m.acceptStates,
+			transitions:  transitions,
+			currentState: start,
+		},
+		States: subsets,
+	}
+}
+
+func newPowersetNamer(naming PowersetNaming) *powersetNamer {
+	return &powersetNamer{
+		naming: naming,
+		used:   set.NewStringSet(),
+	}
+}
+
+// name returns a new name of the sorted states.
+// Primes are appended if the name is duplicated.
+func (s *powersetNamer) name(states []string) string {
+	var x string
+	switch s.naming {
+	case SubsetPowersetNaming:
+		x = subsetName(states)
+	case HashPowersetNaming:
+		h := fnv.New64a()
+		h.Write([]byte(powersetKeyOf(states)))
+		x = fmt.Sprintf("%016x", h.Sum64())
+	default:
+		x = fmt.Sprint(s.used.Len())
+	}
+	x = uniqueName(s.used, x)
+	s.used.Add(x)
+	return x
+}
+
+// subsetName returns the sorted states like {q1,q3}.
+func subsetName(states []string) string { return "{" + strings.Join(states, ",") + "}" }
+
+// powersetKey returns the key that identifies the set of the states.
+func powersetKey(states set.StringSet) string { return powersetKeyOf(sortedStrings(states)) }
+
+func powersetKeyOf(states []string) string {
+	xs := make([]string, len(states))
+	for i, x := range states {
+		xs[i] = strconv.Quote(x)
+	}
+	return strings.Join(xs, ",")
+}
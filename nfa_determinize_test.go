@@ -0,0 +1,126 @@
+package roughfa_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+type nfaDeterminizeTestcase struct {
+	name       string
+	naming     roughfa.PowersetNaming
+	wantStart  string
+	wantStates map[string][]string
+}
+
+func (s nfaDeterminizeTestcase) test(t *testing.T) {
+	// accepts the words whose second last character is a
+	n, err := roughfa.NewNFAMachineBuilder().
+		States([]string{"q0", "q1", "q2"}).
+		StartStates([]string{"q0"}).
+		AcceptStates([]string{"q2"}).
+		Transitions(map[string]map[rune][]string{
+			"q0": {
+				'a': {"q0", "q1"},
+				'b': {"q0"},
+			},
+			"q1": {
+				'a': {"q2"},
+				'b': {"q2"},
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	r := n.Determinize(s.naming)
+	assert.Equal(t, s.wantStart, r.Machine.State(), "start")
+	if s.wantStates != nil {
+		assert.Equal(t, s.wantStates, r.States, "states")
+	}
+	assert.Equal(t, 4, len(r.States), "number of states")
+	assert.ElementsMatch(t, r.Machine.ToShell().States, keysOf(r.States), "provenance")
+	ok, w := roughfa.Equivalent(n, roughfa.FromDFA(r.Machine))
+	assert.True(t, ok, "equivalent, distinguished by %q", w)
+
+	d, err := r.ToDot()
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, x := range []string{`label="{q0,q1,q2}"`, `label="{q0}"`} {
+		assert.True(t, strings.Contains(d.AsDot(), x), "dot has %s", x)
+	}
+}
+
+func keysOf(m map[string][]string) []string {
+	var ks []string
+	for k := range m {
+		ks = append(ks, k)
+	}
+	return ks
+}
+
+func TestNFADeterminize(t *testing.T) {
+	for _, tc := range []*nfaDeterminizeTestcase{
+		{
+			name:      "number",
+			naming:    roughfa.NumberPowersetNaming,
+			wantStart: "0",
+			wantStates: map[string][]string{
+				"0": {"q0"},
+				"1": {"q0", "q1"},
+				"2": {"q0", "q1", "q2"},
+				"3": {"q0", "q2"},
+			},
+		},
+		{
+			name:      "subset",
+			naming:    roughfa.SubsetPowersetNaming,
+			wantStart: "{q0}",
+			wantStates: map[string][]string{
+				"{q0}":       {"q0"},
+				"{q0,q1}":    {"q0", "q1"},
+				"{q0,q1,q2}": {"q0", "q1", "q2"},
+				"{q0,q2}":    {"q0", "q2"},
+			},
+		},
+		{
+			name:      "hash",
+			naming:    roughfa.HashPowersetNaming,
+			wantStart: "cf822483f58f9c32",
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestNFADeterminizeOtherwise(t *testing.T) {
+	// the transition by a is removed by the epsilon expansion because q1 cannot reach the accept state
+	m, err := roughfa.NewNFAMachineBuilder().
+		States([]string{"q0", "q1", "q2"}).
+		StartStates([]string{"q0"}).
+		AcceptStates([]string{"q2"}).
+		Transitions(map[string]map[rune][]string{
+			"q0": {
+				'a':               {"q1"},
+				roughfa.Otherwise: {"q2"},
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	r := m.Determinize(roughfa.SubsetPowersetNaming)
+	d := r.Machine.ToShell()
+	assert.Equal(t, len(d.States), len(r.States))
+	for _, state := range d.States {
+		_, ok := r.States[state]
+		assert.True(t, ok, "%s", state)
+	}
+	sink := d.Transitions["{q0}"]['a']
+	assert.Equal(t, []string{}, r.States[sink])
+	assert.False(t, r.Machine.Accepts("a"))
+	assert.True(t, r.Machine.Accepts("b"))
+}
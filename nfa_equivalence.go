@@ -0,0 +1,104 @@
+package roughfa
+
+type (
+	// hopcroftKarpNode is a state of either machine.
+	// The zero value means the sink state of both machines.
+	hopcroftKarpNode struct {
+		side  int
+		state string
+	}
+	hopcroftKarpPair struct {
+		x hopcroftKarpNode
+		y hopcroftKarpNode
+	}
+)
+
+// Equivalent returns true if a and b accept the same language.
+// Otherwise returns false and a shortest word that is accepted by exactly one of them.
+// A character that is not in the chars of a is rejected by a, and b likewise.
+//
+// a and b are determinized unless they are dfas,
+// and the equivalence is decided by the algorithm of Hopcroft and Karp.
+func Equivalent(a, b NFAMachine) (bool, string) {
+	pa, pb := newProductOperands(a, b)
+	if hopcroftKarp(pa, pb) {
+		return true, ""
+	}
+	w, _ := shortestProductWord(pa, pb, func(x, y bool) bool { return x != y })
+	return false, w
+}
+
+// EquivalentDFA is Equivalent for DFAMachine.
+func EquivalentDFA(a, b DFAMachine) (bool, string) { return Equivalent(FromDFA(a), FromDFA(b)) }
+
+// hopcroftKarp returns true if a and b accept the same language.
+func hopcroftKarp(a, b *productOperand) bool {
+	var (
+		operands = map[int]*productOperand{
+			1: a,
+			2: b,
+		}
+		parent = map[hopcroftKarpNode]hopcroftKarpNode{}
+		find   func(x hopcroftKarpNode) hopcroftKarpNode
+	)
+	find = func(x hopcroftKarpNode) hopcroftKarpNode {
+		p, ok := parent[x]
+		if !ok || p == x {
+			return x
+		}
+		r := find(p)
+		parent[x] = r
+		return r
+	}
+	var (
+		accepts = func(x hopcroftKarpNode) bool {
+			return x.side != 0 && operands[x.side].accepts(x.state)
+		}
+		next = func(x hopcroftKarpNode, c rune) hopcroftKarpNode {
+			if x.side == 0 {
+				return x
+			}
+			state := operands[x.side].next(x.state, c)
+			if state == "" {
+				return hopcroftKarpNode{}
+			}
+			return hopcroftKarpNode{
+				side:  x.side,
+				state: state,
+			}
+		}
+		alphabet = a.m.alphabet()
+		start    = hopcroftKarpPair{
+			x: hopcroftKarpNode{side: 1, state: a.start()},
+			y: hopcroftKarpNode{side: 2, state: b.start()},
+		}
+		q = []hopcroftKarpPair{start}
+	)
+	alphabet.Add(b.m.alphabet().Unwrap()...)
+	chars := sortedRunes(alphabet)
+	parent[start.x] = start.y
+	for len(q) > 0 {
+		p := q[0]
+		q = q[1:]
+		if accepts(p.x) != accepts(p.y) {
+			return false
+		}
+		for _, c := range chars {
+			var (
+				x  = next(p.x, c)
+				y  = next(p.y, c)
+				rx = find(x)
+				ry = find(y)
+			)
+			if rx == ry {
+				continue
+			}
+			parent[rx] = ry
+			q = append(q, hopcroftKarpPair{
+				x: x,
+				y: y,
+			})
+		}
+	}
+	return true
+}
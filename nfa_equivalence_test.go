@@ -0,0 +1,121 @@
+package roughfa_test
+
+import (
+	"regexp"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+type equivalentTestcase struct {
+	name     string
+	patternA string
+	patternB string
+	chars    string
+	want     bool
+}
+
+func (s equivalentTestcase) test(t *testing.T) {
+	a, err := roughfa.CompileRegexp(s.patternA)
+	if !assert.Nil(t, err) {
+		return
+	}
+	b, err := roughfa.CompileRegexp(s.patternB)
+	if !assert.Nil(t, err) {
+		return
+	}
+	got, w := roughfa.Equivalent(a, b)
+	assert.Equal(t, s.want, got)
+	if got {
+		assert.Equal(t, "", w)
+		return
+	}
+	var (
+		ra = regexp.MustCompile(`^(?:` + s.patternA + `)$`)
+		rb = regexp.MustCompile(`^(?:` + s.patternB + `)$`)
+	)
+	t.Logf("counterexample: %q", w)
+	assert.NotEqual(t, ra.MatchString(w), rb.MatchString(w), "counterexample %q", w)
+	if w == "" {
+		return
+	}
+	for _, x := range allWords(s.chars, utf8.RuneCountInString(w)-1) {
+		assert.Equal(t, ra.MatchString(x), rb.MatchString(x), "shorter counterexample %q", x)
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	for _, tc := range []*equivalentTestcase{
+		{
+			name:     "same",
+			patternA: "(a|b)*a",
+			patternB: "(a|b)*a",
+			chars:    "ab",
+			want:     true,
+		},
+		{
+			name:     "refactored",
+			patternA: "(a|b)*",
+			patternB: "(a*b*)*",
+			chars:    "ab",
+			want:     true,
+		},
+		{
+			name:     "star plus",
+			patternA: "a+|",
+			patternB: "a*",
+			chars:    "a",
+			want:     true,
+		},
+		{
+			name:     "empty word",
+			patternA: "a*",
+			patternB: "a+",
+			chars:    "a",
+		},
+		{
+			name:     "long counterexample",
+			patternA: "(a|b)*a(a|b)(a|b)",
+			patternB: "(a|b)*a(a|b)(a|b)|bbbb",
+			chars:    "ab",
+		},
+		{
+			name:     "different alphabets",
+			patternA: "a*",
+			patternB: "(a|b)*",
+			chars:    "ab",
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestEquivalentDFA(t *testing.T) {
+	newMachine := func(accept string) roughfa.DFAMachine {
+		m, err := roughfa.NewDFAMachineBuilder().
+			States([]string{"even", "odd"}).
+			StartState("even").
+			AcceptStates([]string{accept}).
+			Transitions(map[string]map[rune]string{
+				"even": {
+					'1': "odd",
+				},
+				"odd": {
+					'1': "even",
+				},
+			}).
+			Build()
+		if err != nil {
+			panic(err)
+		}
+		return m
+	}
+	got, w := roughfa.EquivalentDFA(newMachine("even"), newMachine("even"))
+	assert.True(t, got)
+	assert.Equal(t, "", w)
+	got, w = roughfa.EquivalentDFA(newMachine("even"), newMachine("odd"))
+	assert.False(t, got)
+	assert.Equal(t, "", w)
+}
@@ -0,0 +1,106 @@
+package roughfa
+
+import "github.com/berquerant/roughfa/internal/set"
+
+type (
+	// inclusionNode is a state of b and the states of a after reading a word.
+	inclusionNode struct {
+		state  string
+		states set.StringSet
+		parent *inclusionNode
+		char   rune
+	}
+)
+
+func (s inclusionNode) word() string {
+	var w []rune
+	for x := &s; x.parent != nil; x = x.parent {
+		w = append(w, x.char)
+	}
+	for i, j := 0, len(w)-1; i < j; i, j = i+1, j-1 {
+		w[i], w[j] = w[j], w[i]
+	}
+	return string(w)
+}
+
+// Includes returns true if a accepts all words accepted by b.
+// Otherwise returns false and a shortest word accepted by b but not by a.
+// A character that is not in the chars of a is rejected by a.
+//
+// Includes explores the pairs of a state of b and a set of the states of a without determinization,
+// and prunes the pairs whose set of a includes the set of another pair with the same state of b,
+// because the smaller set leads to the counterexample if the larger does.
+func Includes(a, b NFAMachine) (bool, string) {
+	var (
+		ma    = asNFAMachine(a)
+		mb    = asNFAMachine(b)
+		chars = ma.explicitChars()
+	)
+	// the otherwise transitions of a and b are by the same characters
+	chars.Add(mb.explicitChars().Unwrap()...)
+	ma = ma.expandOtherwiseBy(chars)
+	mb = mb.expandOtherwiseBy(chars)
+	var (
+		// antichain[p] is the minimal sets of a that have been visited with p
+		antichain = map[string][]set.StringSet{}
+		q         []*inclusionNode
+		visit     = func(n *inclusionNode) {
+			var chain []set.StringSet
+			for _, x := range antichain[n.state] {
+				if n.states.In(x.Unwrap()...) {
+					// x is a subset of n.states
+					return
+				}
+				if !x.In(n.states.Unwrap()...) {
+					chain = append(chain, x)
+				}
+			}
+			antichain[n.state] = append(chain, n.states)
+			q = append(q, n)
+		}
+		aStart = ma.epsilonClosure(ma.startStates)
+	)
+	for _, p := range sortedStrings(mb.epsilonClosure(mb.startStates)) {
+		visit(&inclusionNode{
+			state:  p,
+			states: aStart,
+		})
+	}
+	for len(q) > 0 {
+		n := q[0]
+		q = q[1:]
+		if mb.acceptStates.In(n.state) && n.states.And(ma.acceptStates).Len() == 0 {
+			return false, n.word()
+		}
+		var chars []rune
+		for c := range mb.transitions[n.state] {
+			if c != Epsilon && (mb.chars.Len() == 0 || mb.chars.In(c)) {
+				chars = append(chars, c)
+			}
+		}
+		for _, c := range sortedRunes(set.NewRuneSet(chars...)) {
+			states := set.NewStringSet()
+			if ma.chars.Len() == 0 || ma.chars.In(c) {
+				for _, x := range n.states.Unwrap() {
+					if toStates, ok := ma.move(x, c); ok {
+						states.Add(toStates.Unwrap()...)
+					}
+				}
+				states = ma.epsilonClosure(states)
+			}
+			next := mb.epsilonClosure(mb.transitions[n.state][c])
+			for _, p := range sortedStrings(next) {
+				visit(&inclusionNode{
+					state:  p,
+					states: states,
+					parent: n,
+					char:   c,
+				})
+			}
+		}
+	}
+	return true, ""
+}
+
+// IncludesDFA is Includes for DFAMachine.
+func IncludesDFA(a, b DFAMachine) (bool, string) { return Includes(FromDFA(a), FromDFA(b)) }
@@ -0,0 +1,118 @@
+package roughfa_test
+
+import (
+	"regexp"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+type includesTestcase struct {
+	name     string
+	patternA string
+	patternB string
+	chars    string
+	want     bool
+}
+
+func (s includesTestcase) test(t *testing.T) {
+	a, err := roughfa.CompileRegexp(s.patternA)
+	if !assert.Nil(t, err) {
+		return
+	}
+	b, err := roughfa.CompileRegexp(s.patternB)
+	if !assert.Nil(t, err) {
+		return
+	}
+	got, w := roughfa.Includes(a, b)
+	assert.Equal(t, s.want, got)
+	if got {
+		assert.Equal(t, "", w)
+		return
+	}
+	var (
+		ra = regexp.MustCompile(`^(?:` + s.patternA + `)$`)
+		rb = regexp.MustCompile(`^(?:` + s.patternB + `)$`)
+	)
+	t.Logf("counterexample: %q", w)
+	assert.True(t, rb.MatchString(w) && !ra.MatchString(w), "counterexample %q", w)
+	if w == "" {
+		return
+	}
+	for _, x := range allWords(s.chars, utf8.RuneCountInString(w)-1) {
+		assert.False(t, rb.MatchString(x) && !ra.MatchString(x), "shorter counterexample %q", x)
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	for _, tc := range []*includesTestcase{
+		{
+			name:     "same",
+			patternA: "(a|b)*a",
+			patternB: "(a|b)*a",
+			chars:    "ab",
+			want:     true,
+		},
+		{
+			name:     "subset",
+			patternA: "a(b|c)*",
+			patternB: "ab*c?",
+			chars:    "abc",
+			want:     true,
+		},
+		{
+			name:     "superset",
+			patternA: "ab*c?",
+			patternB: "a(b|c)*",
+			chars:    "abc",
+		},
+		{
+			name:     "empty word",
+			patternA: "a+",
+			patternB: "a*",
+			chars:    "a",
+		},
+		{
+			name:     "nondeterministic",
+			patternA: "(a|b)*a(a|b)(a|b)",
+			patternB: "(a|b)*aa(a|b)|(a|b)*ab(a|b)|bbb",
+			chars:    "ab",
+		},
+		{
+			name:     "empty language",
+			patternA: "a",
+			patternB: "a(b|)c",
+			chars:    "abc",
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestIncludesChars(t *testing.T) {
+	a, err := roughfa.NewNFAMachineBuilder().
+		States([]string{"0"}).
+		Chars([]rune{'a'}).
+		StartStates([]string{"0"}).
+		AcceptStates([]string{"0"}).
+		Transitions(map[string]map[rune][]string{
+			"0": {
+				'a': {"0"},
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	b, err := roughfa.CompileRegexp("a*b")
+	if !assert.Nil(t, err) {
+		return
+	}
+	got, w := roughfa.Includes(a, b)
+	assert.False(t, got)
+	assert.Equal(t, "b", w)
+	got, _ = roughfa.Includes(b, a)
+	assert.False(t, got)
+}
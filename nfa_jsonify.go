@@ -1,60 +1,148 @@
 package roughfa
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"unicode/utf8"
+
+	"github.com/berquerant/roughfa/internal/set"
+)
 
 const (
-	// EpsilonForJSON is for the epsilon transition for jsonify.
+	// EpsilonForJSON is for the epsilon transition for jsonify in the version 1.
 	EpsilonForJSON = '\a'
 )
 
 type (
 	// NFAMachineShell is a serializable form of NFAMachine.
+	// See JSONShellVersion for the json format.
 	NFAMachineShell struct {
-		States         []string                       `json:"states"`
-		Chars          []rune                         `json:"-"`
-		RawChars       []string                       `json:"chars,omitempty"`
-		StartStates    []string                       `json:"start_states"`
-		AcceptStates   []string                       `json:"accept_states"`
-		Transitions    map[string]map[rune][]string   `json:"-"`
+		States []string `json:"states"`
+		Chars  []rune   `json:"-"`
+		// Deprecated: RawChars is the chars of the json in the version 1, use ToJSON and Chars instead.
+		RawChars     []string                     `json:"chars,omitempty"`
+		StartStates  []string                     `json:"start_states"`
+		AcceptStates []string                     `json:"accept_states"`
+		Transitions  map[string]map[rune][]string `json:"-"`
+		// Deprecated: RawTransitions is the transitions of the json in the version 1, use ToJSON and Transitions instead.
 		RawTransitions map[string]map[string][]string `json:"transitions"`
 		CurrentStates  []string                       `json:"current_states,omitempty"`
 	}
+
+	nfaMachineShellJSON struct {
+		Version       int                           `json:"version"`
+		States        []string                      `json:"states"`
+		Chars         []string                      `json:"chars,omitempty"`
+		StartStates   []string                      `json:"start_states"`
+		AcceptStates  []string                      `json:"accept_states"`
+		Transitions   map[string]*nfaStateShellJSON `json:"transitions"`
+		CurrentStates []string                      `json:"current_states,omitempty"`
+	}
+
+	nfaStateShellJSON struct {
+		On        map[string][]string `json:"on,omitempty"`
+		Epsilon   []string            `json:"epsilon,omitempty"`
+		Otherwise []string            `json:"otherwise,omitempty"`
+	}
 )
 
 func (s NFAMachineShell) ToJSON() ([]byte, error) {
-	rcs := make([]string, len(s.Chars))
-	for i, x := range s.Chars {
-		rcs[i] = string(x)
-	}
-	rts := make(map[string]map[string][]string, len(s.Transitions))
+	ts := make(map[string]*nfaStateShellJSON, len(s.Transitions))
 	for k, x := range s.Transitions {
-		rts[k] = make(map[string][]string, len(x))
-		for kx, kv := range x {
-			if kx == Epsilon {
-				rts[k][string(EpsilonForJSON)] = kv
-				continue
+		var (
+			state = &nfaStateShellJSON{}
+			dests = map[string][]string{}
+			cs    []rune
+			key   = func(c rune) string { return powersetKey(set.NewStringSet(x[c]...)) }
+		)
+		for c, toStates := range x {
+			switch c {
+			case Epsilon:
+				state.Epsilon = toStates
+			case Otherwise:
+				state.Otherwise = toStates
+			default:
+				dests[key(c)] = toStates
+				cs = append(cs, c)
+			}
+		}
+		if len(cs) > 0 {
+			state.On = map[string][]string{}
+		}
+		for g, labels := range groupJSONLabels(cs, key) {
+			for _, label := range labels {
+				state.On[label] = dests[g]
 			}
-			rts[k][string(kx)] = kv
 		}
+		ts[k] = state
 	}
-	return json.Marshal(NFAMachineShell{
-		States:         s.States,
-		RawChars:       rcs,
-		StartStates:    s.StartStates,
-		AcceptStates:   s.AcceptStates,
-		RawTransitions: rts,
-		CurrentStates:  s.CurrentStates,
+	return json.Marshal(nfaMachineShellJSON{
+		Version:       JSONShellVersion,
+		States:        s.States,
+		Chars:         formatJSONLabels(s.Chars),
+		StartStates:   s.StartStates,
+		AcceptStates:  s.AcceptStates,
+		Transitions:   ts,
+		CurrentStates: s.CurrentStates,
 	})
 }
 
+// NewNFAMachineShellFromJSON creates a NFAMachineShell from the json of any version.
 func NewNFAMachineShellFromJSON(b []byte) (*NFAMachineShell, error) {
+	v, err := jsonShellVersionOf(b)
+	if err != nil {
+		return nil, err
+	}
+	if v == 1 {
+		return newNFAMachineShellFromJSONV1(b)
+	}
+	var s nfaMachineShellJSON
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	cs, err := parseJSONChars(s.Chars)
+	if err != nil {
+		return nil, err
+	}
+	ts := make(map[string]map[rune][]string, len(s.Transitions))
+	for k, x := range s.Transitions {
+		ts[k] = map[rune][]string{}
+		if x == nil {
+			continue
+		}
+		for label, toStates := range x.On {
+			r, err := parseJSONLabel(label, Epsilon, Otherwise)
+			if err != nil {
+				return nil, err
+			}
+			for c := r.Lo; c <= r.Hi; c++ {
+				ts[k][c] = append(ts[k][c], toStates...)
+			}
+		}
+		if x.Epsilon != nil {
+			ts[k][Epsilon] = x.Epsilon
+		}
+		if x.Otherwise != nil {
+			ts[k][Otherwise] = x.Otherwise
+		}
+	}
+	return &NFAMachineShell{
+		States:        s.States,
+		Chars:         cs,
+		StartStates:   s.StartStates,
+		AcceptStates:  s.AcceptStates,
+		Transitions:   ts,
+		CurrentStates: s.CurrentStates,
+	}, nil
+}
+
+func newNFAMachineShellFromJSONV1(b []byte) (*NFAMachineShell, error) {
 	var s NFAMachineShell
 	if err := json.Unmarshal(b, &s); err != nil {
 		return nil, err
 	}
 	cs := make([]rune, len(s.RawChars))
 	for i, x := range s.RawChars {
-		if len(x) != 1 {
+		if utf8.RuneCountInString(x) != 1 {
 			return nil, ErrCannotUnmarshalMachine
 		}
 		cs[i] = []rune(x)[0]
@@ -63,7 +151,7 @@ func NewNFAMachineShellFromJSON(b []byte) (*NFAMachineShell, error) {
 	for k, x := range s.RawTransitions {
 		ts[k] = make(map[rune][]string, len(x))
 		for kx, kv := range x {
-			if len(kx) != 1 {
+			if utf8.RuneCountInString(kx) != 1 {
 				return nil, ErrCannotUnmarshalMachine
 			}
 			c := []rune(kx)[0]
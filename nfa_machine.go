@@ -1,10 +1,6 @@
 package roughfa
 
 import (
-	"fmt"
-	"sort"
-	"strings"
-
 	"github.com/berquerant/roughfa/internal/dot"
 	"github.com/berquerant/roughfa/internal/set"
 )
@@ -12,46 +8,63 @@ import (
 const (
 	// Epsilon is for the epsilon transition.
 	Epsilon = 'ε'
+	// sinkState is the name of the state that is added to have all transitions.
+	sinkState = "sink"
 )
 
 type (
 	// NFAMachine is a runner of the non deterministic finite automaton.
+	// See ToGenericNFA for the machine over the symbols other than runes.
 	NFAMachine interface {
+		Machine
 		// States returns the current states.
 		States() []string
 		// SetStates sets the states as the current states.
 		// Returns an error if a invalid state in the states exists.
 		SetStates(states []string) error
-		// Put inputs a character.
-		// Returns an error if invalid input or no transitions.
-		Put(x rune) error
-		// IsAccepted returns true if the current states are acceptable.
-		IsAccepted() bool
-		// Reset resets the current states to the start state.
-		Reset()
 		// ToShell generates NFAMachineShell.
 		ToShell() *NFAMachineShell
 		// ApplyEpsilonExpansion creates a new Machine that applied the epsilon expansion from this NFAMachine.
 		// The states that are not an accept state and have no outbound transitions.
 		ApplyEpsilonExpansion() NFAMachine
 		// ApplyPowersetConstruction creates a new NFAMachine that applied the powerset construction.
+		// The states are named by sequential numbers.
 		ApplyPowersetConstruction() (NFAMachine, error)
-		// ToDot generates Dot.
-		ToDot() (dot.Dot, error)
+		// Determinize creates a dfa by the epsilon expansion and the powerset construction.
+		// The states of the dfa are named by naming, and the result has the states of this
+		// that each state of the dfa represents.
+		Determinize(naming PowersetNaming) *DeterminizeResult
 		// HasEpsilon returns true if this has an epsilon transition.
 		HasEpsilon() bool
-		// IsDFA returns true if this is a dfa.
-		IsDFA() bool
 		// ToDFA creates a dfa from this.
 		// Returns an error if this is not a dfa.
 		ToDFA() (DFAMachine, error)
 		// Not creates a Machine whose accept states are the diff of the states and the original accept states.
+		// Not is not the complement of the language unless this is a dfa that has all transitions,
+		// use Complement instead.
 		Not() NFAMachine
+		// Complement creates a dfa that accepts the words over the chars that this does not accept.
+		// If the chars are universe, the chars of the transitions are used as the chars of the dfa.
+		// The dfa is created by removing epsilon transitions, the powerset construction unless this is a dfa,
+		// adding a sink state that is named sink or sink followed by primes to have all transitions,
+		// and swapping the accept states and the others.
+		Complement() NFAMachine
 		// Reverse creates a NFAMachine whose start states and accept states are reversed,
 		// and transitions are reversed also.
 		Reverse() NFAMachine
 		// Minimize minimizes NFAMachine.
 		Minimize() (NFAMachine, error)
+		// Complete creates a NFAMachine that has the transitions for all chars from all states,
+		// by adding a sink state that is named sink or sink followed by primes.
+		// If the chars are universe, the chars of the transitions are used as the chars.
+		// The sink state is not added if this has all transitions already.
+		Complete() NFAMachine
+		// Trim creates a NFAMachine without the states that are unreachable from the start states
+		// or cannot reach an accept state.
+		// Trim removes all the states, including the start states, if this accepts no words.
+		Trim() NFAMachine
+		// Compile creates a CompiledNFA that accepts the same words as this.
+		Compile() CompiledNFA
 	}
 
 	nfaMachine struct {
@@ -84,6 +97,26 @@ func FromDFA(m DFAMachine) NFAMachine {
 	}
 }
 
+// asNFAMachine creates a nfaMachine that has the same definition and current states as m.
+func asNFAMachine(m NFAMachine) *nfaMachine {
+	s := m.ToShell()
+	ts := make(map[string]map[rune]set.StringSet, len(s.Transitions))
+	for fromState, x := range s.Transitions {
+		ts[fromState] = make(map[rune]set.StringSet, len(x))
+		for c, toStates := range x {
+			ts[fromState][c] = set.NewStringSet(toStates...)
+		}
+	}
+	return &nfaMachine{
+		states:        set.NewStringSet(s.States...),
+		startStates:   set.NewStringSet(s.StartStates...),
+		chars:         set.NewRuneSet(s.Chars...),
+		acceptStates:  set.NewStringSet(s.AcceptStates...),
+		currentStates: set.NewStringSet(s.CurrentStates...),
+		transitions:   ts,
+	}
+}
+
 // ExpandEpsilon expands epsilon transitions.
 // Generated transitions has no epsilon transitions.
 func ExpandEpsilon(transitions map[string]map[rune]set.StringSet) map[string]map[rune]set.StringSet {
@@ -139,6 +172,8 @@ func (s nfaMachine) Minimize() (NFAMachine, error) {
 }
 
 func (s nfaMachine) Reverse() NFAMachine {
+	// the otherwise transition depends on the other transitions from the state
+	s = *s.expandOtherwise()
 	transitions := map[string]map[rune]set.StringSet{}
 	for fromState, x := range s.transitions {
 		for c, toStates := range x {
@@ -153,7 +188,7 @@ func (s nfaMachine) Reverse() NFAMachine {
 			}
 		}
 	}
-	return &nfaMachine{
+	m := &nfaMachine{
 		states:        s.states.Clone(),
 		chars:         s.chars.Clone(),
 		transitions:   transitions,
@@ -161,9 +196,61 @@ func (s nfaMachine) Reverse() NFAMachine {
 		acceptStates:  s.startStates.Clone(),
 		currentStates: s.acceptStates.Clone(),
 	}
+	return m.blockOtherwise(s.explicitChars())
+}
+
+func (s nfaMachine) Complete() NFAMachine { return s.complete() }
+
+func (s nfaMachine) Trim() NFAMachine {
+	return s.restrict(s.reachableStates().And(s.coReachableStates()))
+}
+
+func (s nfaMachine) Complement() NFAMachine  { return s.complement() }
+func (s nfaMachine) complement() *nfaMachine { return s.determinize().complete().not() }
+
+// complete creates a nfaMachine that has the transitions for all chars from all states,
+// by adding a sink state that has no accept states.
+// The chars of the created machine are the alphabet of this.
+func (s nfaMachine) complete() *nfaMachine {
+	s = *s.expandOtherwise()
+	var (
+		alphabet    = s.alphabet()
+		sink        = uniqueName(s.states, sinkState)
+		states      = s.states.Clone()
+		transitions = s.cloneTransitions()
+	)
+	for _, state := range s.states.Unwrap() {
+		for _, c := range alphabet.Unwrap() {
+			if toStates, ok := transitions[state][c]; ok && toStates.Len() > 0 {
+				continue
+			}
+			addTransitions(transitions, state, c, sink)
+			states.Add(sink)
+		}
+	}
+	if states.In(sink) {
+		for _, c := range alphabet.Unwrap() {
+			addTransitions(transitions, sink, c, sink)
+		}
+	}
+	chars := completedChars(s.chars, alphabet)
+	if chars.Len() > 0 && s.HasEpsilon() {
+		// the epsilon transitions remain
+		chars.Add(Epsilon)
+	}
+	return &nfaMachine{
+		states:        states,
+		chars:         chars,
+		startStates:   s.startStates.Clone(),
+		acceptStates:  s.acceptStates.Clone(),
+		transitions:   transitions,
+		currentStates: s.currentStates.Clone(),
+	}
 }
 
-func (s nfaMachine) Not() NFAMachine {
+func (s nfaMachine) Not() NFAMachine { return s.not() }
+
+func (s nfaMachine) not() *nfaMachine {
 	acceptStates := s.states.Clone()
 	acceptStates.Del(s.acceptStates.Unwrap()...)
 	return &nfaMachine{
@@ -235,52 +322,239 @@ func (s nfaMachine) ToDot() (dot.Dot, error) {
 		Build()
 }
 
-func (s *nfaMachine) applyEpsilon() {
-	modified := true
-	for modified {
-		modified = false
-		for _, state := range s.currentStates.Unwrap() {
-			t, ok := s.transitions[state]
-			if !ok {
+// epsilonClosure returns the states reachable from the states by epsilon transitions, including the states.
+func (s nfaMachine) epsilonClosure(states set.StringSet) set.StringSet {
+	var (
+		closure = states.Clone()
+		q       = states.Unwrap()
+	)
+	for len(q) > 0 {
+		state := q[0]
+		q = q[1:]
+		t, ok := s.transitions[state]
+		if !ok {
+			continue
+		}
+		toStates, ok := t[Epsilon]
+		if !ok {
+			continue
+		}
+		for _, x := range toStates.Unwrap() {
+			if closure.In(x) {
 				continue
 			}
-			if u, ok := t[Epsilon]; ok {
-				states := set.NewStringSet(s.currentStates.Unwrap()...)
-				s.currentStates.Del(state)
-				s.currentStates.Add(u.Unwrap()...)
-				if !modified {
-					modified = !states.Equal(s.currentStates)
+			closure.Add(x)
+			q = append(q, x)
+		}
+	}
+	return closure
+}
+
+// alphabet returns the chars except epsilon and otherwise,
+// or the chars of the transitions except epsilon if the chars are universe.
+func (s nfaMachine) alphabet() set.RuneSet {
+	cs := set.NewRuneSet()
+	if s.chars.Len() > 0 {
+		cs.Add(s.chars.Unwrap()...)
+		cs.Del(Epsilon, Otherwise)
+		return cs
+	}
+	for _, x := range s.transitions {
+		for c := range x {
+			if c != Epsilon {
+				cs.Add(c)
+			}
+		}
+	}
+	return cs
+}
+
+// dfaNext returns the next state of the dfa.
+// Returns false if no transitions.
+func (s nfaMachine) dfaNext(state string, c rune) (string, bool) {
+	toStates, ok := s.move(state, c)
+	if !ok || toStates.Len() == 0 {
+		return "", false
+	}
+	return toStates.Unwrap()[0], true
+}
+
+// reachableStates returns the states reachable from the start states.
+func (s nfaMachine) reachableStates() set.StringSet {
+	var (
+		states = s.startStates.Clone()
+		q      = s.startStates.Unwrap()
+	)
+	for len(q) > 0 {
+		state := q[0]
+		q = q[1:]
+		for _, toStates := range s.transitions[state] {
+			for _, x := range toStates.Unwrap() {
+				if states.In(x) {
+					continue
+				}
+				states.Add(x)
+				q = append(q, x)
+			}
+		}
+	}
+	return states
+}
+
+// coReachableStates returns the states from which an accept state is reachable.
+func (s nfaMachine) coReachableStates() set.StringSet {
+	reversed := map[string]set.StringSet{}
+	for fromState, x := range s.transitions {
+		for _, toStates := range x {
+			for _, toState := range toStates.Unwrap() {
+				if _, ok := reversed[toState]; !ok {
+					reversed[toState] = set.NewStringSet()
 				}
+				reversed[toState].Add(fromState)
+			}
+		}
+	}
+	var (
+		states = s.acceptStates.Clone()
+		q      = s.acceptStates.Unwrap()
+	)
+	for len(q) > 0 {
+		state := q[0]
+		q = q[1:]
+		fromStates, ok := reversed[state]
+		if !ok {
+			continue
+		}
+		for _, x := range fromStates.Unwrap() {
+			if states.In(x) {
+				continue
 			}
+			states.Add(x)
+			q = append(q, x)
+		}
+	}
+	return states
+}
+
+// cloneTransitions returns a deep copy of the transitions.
+func (s nfaMachine) cloneTransitions() map[string]map[rune]set.StringSet {
+	ts := make(map[string]map[rune]set.StringSet, len(s.transitions))
+	for fromState, x := range s.transitions {
+		ts[fromState] = make(map[rune]set.StringSet, len(x))
+		for c, toStates := range x {
+			ts[fromState][c] = toStates.Clone()
+		}
+	}
+	return ts
+}
+
+// renamed creates a nfaMachine whose states are prefixed by prefix.
+func (s nfaMachine) renamed(prefix string) *nfaMachine {
+	rename := func(x set.StringSet) set.StringSet {
+		y := set.NewStringSet()
+		for _, state := range x.Unwrap() {
+			y.Add(prefix + state)
+		}
+		return y
+	}
+	ts := make(map[string]map[rune]set.StringSet, len(s.transitions))
+	for fromState, x := range s.transitions {
+		ts[prefix+fromState] = make(map[rune]set.StringSet, len(x))
+		for c, toStates := range x {
+			ts[prefix+fromState][c] = rename(toStates)
 		}
 	}
+	return &nfaMachine{
+		states:        rename(s.states),
+		chars:         s.chars.Clone(),
+		startStates:   rename(s.startStates),
+		acceptStates:  rename(s.acceptStates),
+		transitions:   ts,
+		currentStates: rename(s.currentStates),
+	}
+}
+
+// trimDeadStates creates a nfaMachine without the states that cannot reach an accept state,
+// except the start states.
+func (s nfaMachine) trimDeadStates() *nfaMachine {
+	live := s.coReachableStates()
+	live.Add(s.startStates.Unwrap()...)
+	return s.restrict(live)
 }
 
-func (s nfaMachine) applyEpsilonToStartStates() set.StringSet {
+// restrict creates a nfaMachine that consists of the states.
+// The transitions by a char to the removed states from a state that has the otherwise transition
+// are replaced by the transitions to a dead state, so that the otherwise transition is not taken by the char.
+func (s nfaMachine) restrict(states set.StringSet) *nfaMachine {
 	var (
-		startStates = s.startStates.Clone()
-		modified    = true
+		transitions = map[string]map[rune]set.StringSet{}
+		newStates   = s.states.And(states)
+		sink        = uniqueName(s.states, sinkState)
 	)
-	for modified {
-		modified = false
-		for _, state := range startStates.Unwrap() {
-			t, ok := s.transitions[state]
-			if !ok {
+	for fromState, x := range s.transitions {
+		if !states.In(fromState) {
+			continue
+		}
+		var (
+			routes  = map[rune]set.StringSet{}
+			removed []rune
+		)
+		for c, toStates := range x {
+			if y := toStates.And(states); y.Len() > 0 {
+				routes[c] = y
 				continue
 			}
-			if toStates, ok := t[Epsilon]; ok {
-				states := startStates.Clone()
-				startStates.Add(toStates.Unwrap()...)
-				if !modified {
-					modified = states.Equal(startStates)
-				}
+			if c != Otherwise && c != Epsilon {
+				removed = append(removed, c)
+			}
+		}
+		if _, ok := routes[Otherwise]; ok {
+			for _, c := range removed {
+				routes[c] = set.NewStringSet(sink)
+				newStates.Add(sink)
 			}
 		}
+		if len(routes) > 0 {
+			transitions[fromState] = routes
+		}
+	}
+	return &nfaMachine{
+		states:        newStates,
+		chars:         s.chars.Clone(),
+		startStates:   s.startStates.And(states),
+		acceptStates:  s.acceptStates.And(states),
+		transitions:   transitions,
+		currentStates: s.currentStates.And(states),
 	}
-	return startStates
 }
 
-func (s nfaMachine) ApplyEpsilonExpansion() NFAMachine {
+func (s *nfaMachine) applyEpsilon() { s.currentStates = s.epsilonApplied(s.currentStates) }
+
+// epsilonApplied returns the epsilon closure of the states
+// without the states that are only passed through.
+func (s nfaMachine) epsilonApplied(current set.StringSet) set.StringSet {
+	states := s.epsilonClosure(current)
+	// exclude the states that are only passed through
+	for _, state := range states.Unwrap() {
+		if s.acceptStates.In(state) {
+			continue
+		}
+		t := s.transitions[state]
+		if _, ok := t[Epsilon]; ok && len(t) == 1 {
+			states.Del(state)
+		}
+	}
+	return states
+}
+
+func (s nfaMachine) applyEpsilonToStartStates() set.StringSet { return s.epsilonClosure(s.startStates) }
+
+func (s nfaMachine) ApplyEpsilonExpansion() NFAMachine { return s.applyEpsilonExpansion() }
+
+func (s nfaMachine) applyEpsilonExpansion() *nfaMachine {
+	// the otherwise transitions from the states reachable by epsilon transitions
+	// are not the otherwise transitions from the state
+	s = *s.expandOtherwise()
 	var (
 		transitions   = ExpandEpsilon(s.transitions)
 		uselessStates = set.NewStringSet()
@@ -341,68 +615,80 @@ func (s nfaMachine) ApplyPowersetConstruction() (NFAMachine, error) {
 	if s.HasEpsilon() {
 		return nil, ErrEpsilonExists
 	}
+	return s.powersetConstruction(), nil
+}
+
+// determinize creates a dfa that accepts the same language as this.
+// Returns this if this is a dfa already.
+func (s nfaMachine) determinize() *nfaMachine {
+	m := s.expandOtherwise()
+	if m.IsDFA() {
+		return m
+	}
+	return m.applyEpsilonExpansion().powersetConstruction()
+}
+
+// powersetConstruction requires no epsilon transitions.
+// The states are named by sequential numbers.
+func (s nfaMachine) powersetConstruction() *nfaMachine {
+	m, _ := s.namedPowersetConstruction(NumberPowersetNaming)
+	return m
+}
 
-	const dfaStartState = "0"
+// namedPowersetConstruction requires no epsilon transitions.
+// Returns the dfa and the map from the states of the dfa to the sorted states of this.
+func (s nfaMachine) namedPowersetConstruction(naming PowersetNaming) (*nfaMachine, map[string][]string) {
+	s = *s.expandOtherwise()
 	var (
 		states       = set.NewStringSet()
 		transitions  = map[string]map[rune]string{}
 		acceptStates = set.NewStringSet()
-		chars        = func() set.RuneSet {
-			cs := set.NewRuneSet()
-			if s.chars.Len() > 0 {
-				cs.Add(s.chars.Unwrap()...)
-				return cs
+		chars        = s.alphabet()
+		subsets      = map[string][]string{}
+		namer        = newPowersetNamer(naming)
+		// dfaStatesMap is the map from the key of the subset to the state of the dfa
+		dfaStatesMap = map[string]string{}
+		nameOf       = func(x set.StringSet) (string, bool) {
+			k := powersetKey(x)
+			if name, ok := dfaStatesMap[k]; ok {
+				return name, false
 			}
-			for _, x := range s.transitions {
-				for c := range x {
-					cs.Add(c)
-				}
-			}
-			return cs
-		}()
-		stringSetToString = func(x set.StringSet) string {
-			y := x.Unwrap()
-			sort.Strings(y)
-			// TODO: use hash
-			return strings.Join(y, "_")
-		}
-		dfaStatesMap = map[string]string{
-			stringSetToString(s.startStates): dfaStartState,
-		}
-		q = []set.StringSet{set.NewStringSet(s.startStates.Unwrap()...)}
+			subset := sortedStrings(x)
+			name := namer.name(subset)
+			dfaStatesMap[k] = name
+			subsets[name] = subset
+			return name, true
+		}
+		dfaStartState, _ = nameOf(s.startStates)
+		q                = []set.StringSet{set.NewStringSet(s.startStates.Unwrap()...)}
 	)
 
 	for len(q) > 0 {
 		dState := q[0]
 		q = q[1:]
-		states.Add(dfaStatesMap[stringSetToString(dState)])
+		k, _ := nameOf(dState)
+		states.Add(k)
 		if dState.And(s.acceptStates).Len() > 0 {
-			acceptStates.Add(dfaStatesMap[stringSetToString(dState)])
+			acceptStates.Add(k)
 		}
-		for _, c := range chars.Unwrap() {
+		for _, c := range sortedRunes(chars) {
 			dNext := set.NewStringSet()
 			for _, x := range dState.Unwrap() {
-				t, ok := s.transitions[x]
-				if !ok {
-					continue
-				}
-				if y, ok := t[c]; ok {
+				if y, ok := s.move(x, c); ok {
 					dNext.Add(y.Unwrap()...)
 				}
 			}
 			if dNext.Len() == 0 {
 				continue
 			}
-			if _, ok := dfaStatesMap[stringSetToString(dNext)]; !ok {
+			next, isNew := nameOf(dNext)
+			if isNew {
 				q = append(q, dNext)
-				newState := fmt.Sprint(len(dfaStatesMap))
-				dfaStatesMap[stringSetToString(dNext)] = newState
 			}
-			k := dfaStatesMap[stringSetToString(dState)]
 			if _, ok := transitions[k]; !ok {
 				transitions[k] = map[rune]string{}
 			}
-			transitions[k][c] = dfaStatesMap[stringSetToString(dNext)]
+			transitions[k][c] = next
 		}
 	}
 
@@ -413,54 +699,65 @@ func (s nfaMachine) ApplyPowersetConstruction() (NFAMachine, error) {
 			ts[fromState][c] = set.NewStringSet(toState)
 		}
 	}
-	return &nfaMachine{
+	m := &nfaMachine{
 		states:        states,
-		chars:         chars,
+		chars:         completedChars(s.chars, chars),
 		startStates:   set.NewStringSet(dfaStartState),
 		acceptStates:  acceptStates,
 		transitions:   ts,
 		currentStates: set.NewStringSet(dfaStartState),
-	}, nil
-
+	}
+	m = m.blockOtherwise(s.explicitChars())
+	for _, state := range m.states.Unwrap() {
+		if _, ok := subsets[state]; !ok {
+			// the dead state of blockOtherwise
+			subsets[state] = []string{}
+		}
+	}
+	return m, subsets
 }
 
 func (s *nfaMachine) Put(x rune) error {
-	if s.chars.Len() > 0 && !s.chars.In(x) {
-		return ErrInvalidInputChar
+	nextStates, err := s.next(s.currentStates, x)
+	if nextStates != nil {
+		s.currentStates = nextStates
 	}
-	if s.currentStates.Len() == 0 {
-		return ErrEmptyStates
+	return err
+}
+
+// next returns the states after reading x from the states.
+// Returns the empty states and ErrEmptyStates if no transitions.
+// Returns nil if the states are not changed.
+func (s nfaMachine) next(states set.StringSet, x rune) (set.StringSet, error) {
+	if s.chars.Len() > 0 && !s.chars.In(x) {
+		return nil, ErrInvalidInputChar
 	}
-	if s.HasEpsilon() {
-		s.applyEpsilon()
+	if states.Len() == 0 {
+		return nil, ErrEmptyStates
 	}
-	css := make([]set.StringSet, s.currentStates.Len())
-	for i, state := range s.currentStates.Unwrap() {
-		css[i] = set.NewStringSet()
-		t, ok := s.transitions[state]
-		if !ok {
-			continue
-		}
-		if u, ok := t[x]; ok {
-			css[i].Add(u.Unwrap()...)
-		}
+	hasEpsilon := s.HasEpsilon()
+	if hasEpsilon {
+		states = s.epsilonApplied(states)
 	}
 	nextStates := set.NewStringSet()
-	for _, cs := range css {
-		nextStates.Add(cs.Unwrap()...)
+	for _, state := range states.Unwrap() {
+		if u, ok := s.move(state, x); ok {
+			nextStates.Add(u.Unwrap()...)
+		}
 	}
-	s.currentStates = nextStates
-	if s.HasEpsilon() {
-		s.applyEpsilon()
+	if hasEpsilon {
+		nextStates = s.epsilonApplied(nextStates)
 	}
-	if s.currentStates.Len() == 0 {
-		return ErrEmptyStates
+	if nextStates.Len() == 0 {
+		return nextStates, ErrEmptyStates
 	}
-	return nil
+	return nextStates, nil
 }
 func (s nfaMachine) States() []string { return s.currentStates.Unwrap() }
-func (s nfaMachine) IsAccepted() bool { return s.currentStates.And(s.acceptStates).Len() > 0 }
-func (s *nfaMachine) Reset()          { s.currentStates = set.NewStringSet(s.startStates.Unwrap()...) }
+func (s nfaMachine) IsAccepted() bool {
+	return s.epsilonClosure(s.currentStates).And(s.acceptStates).Len() > 0
+}
+func (s *nfaMachine) Reset() { s.currentStates = set.NewStringSet(s.startStates.Unwrap()...) }
 func (s *nfaMachine) SetStates(states []string) error {
 	x := set.NewStringSet(states...)
 	if !s.states.In(x.Unwrap()...) {
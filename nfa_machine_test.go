@@ -2,6 +2,7 @@ package roughfa_test
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"testing"
 
@@ -487,3 +488,143 @@ func TestNFAMachine(t *testing.T) {
 		t.Run(tc.name, tc.test)
 	}
 }
+
+func TestNFAMachineEpsilonClosure(t *testing.T) {
+	// s has both the epsilon transition and the transition by a
+	m, err := roughfa.NewNFAMachineBuilder().
+		States([]string{"s", "f", "g"}).
+		StartStates([]string{"s"}).
+		AcceptStates([]string{"f", "g"}).
+		Transitions(map[string]map[rune][]string{
+			"s": {
+				roughfa.Epsilon: {"f"},
+				'a':             {"g"},
+			},
+			"f": {
+				'b': {"f"},
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	// the accept state reachable by the epsilon transition is considered
+	assert.True(t, m.IsAccepted())
+	// s is not removed by the epsilon transition
+	assert.Nil(t, m.Put('a'))
+	assert.True(t, m.IsAccepted())
+	assert.Equal(t, []string{"g"}, m.States())
+	m.Reset()
+	assert.Nil(t, m.Put('b'))
+	assert.Equal(t, []string{"f"}, m.States())
+}
+
+type complementTestcase struct {
+	name    string
+	machine func() (roughfa.NFAMachine, error)
+	// accept returns true if the original machine accepts the input.
+	accept func(input string) bool
+	chars  string
+}
+
+func (s complementTestcase) test(t *testing.T) {
+	m, err := s.machine()
+	if !assert.Nil(t, err) {
+		return
+	}
+	c := m.Complement()
+	assert.True(t, c.IsDFA())
+	cc := c.Complement()
+	for _, w := range allWords(s.chars, 6) {
+		assert.Equal(t, !s.accept(w), nfaAccepts(c, w), "%q", w)
+		assert.Equal(t, s.accept(w), nfaAccepts(cc, w), "double %q", w)
+	}
+}
+
+func TestComplement(t *testing.T) {
+	regexpAccept := func(pattern string) func(string) bool {
+		r := regexp.MustCompile(`^(?:` + pattern + `)$`)
+		return r.MatchString
+	}
+	for _, tc := range []*complementTestcase{
+		{
+			name: "nondeterministic",
+			machine: func() (roughfa.NFAMachine, error) {
+				return roughfa.NewNFAMachineBuilder().
+					States([]string{"0", "1", "2"}).
+					Chars([]rune{'a', 'b'}).
+					StartStates([]string{"0"}).
+					AcceptStates([]string{"2"}).
+					Transitions(map[string]map[rune][]string{
+						"0": {
+							'a': {"0", "1"},
+							'b': {"0"},
+						},
+						"1": {
+							'b': {"2"},
+						},
+					}).
+					Build()
+			},
+			accept: regexpAccept("(a|b)*ab"),
+			chars:  "ab",
+		},
+		{
+			name: "epsilon",
+			machine: func() (roughfa.NFAMachine, error) {
+				return roughfa.CompileRegexp("a*b?")
+			},
+			accept: regexpAccept("a*b?"),
+			chars:  "ab",
+		},
+		{
+			name: "partial dfa",
+			machine: func() (roughfa.NFAMachine, error) {
+				return roughfa.NewNFAMachineBuilder().
+					States([]string{"0", "1"}).
+					Chars([]rune{'a', 'b', 'c'}).
+					StartStates([]string{"0"}).
+					AcceptStates([]string{"1"}).
+					Transitions(map[string]map[rune][]string{
+						"0": {
+							'a': {"1"},
+						},
+						"1": {
+							'b': {"0"},
+						},
+					}).
+					Build()
+			},
+			accept: regexpAccept("(ab)*a"),
+			chars:  "abc",
+		},
+		{
+			name: "empty language",
+			machine: func() (roughfa.NFAMachine, error) {
+				return roughfa.NewNFAMachineBuilder().
+					States([]string{"0"}).
+					Chars([]rune{'a'}).
+					StartStates([]string{"0"}).
+					AcceptStates([]string{}).
+					Build()
+			},
+			accept: func(string) bool { return false },
+			chars:  "a",
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestComplementChars(t *testing.T) {
+	m, err := roughfa.CompileRegexp("ab")
+	if !assert.Nil(t, err) {
+		return
+	}
+	c := m.Complement()
+	chars := c.ToShell().Chars
+	sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+	assert.Equal(t, []rune{'a', 'b'}, chars)
+	c.Reset()
+	assert.Equal(t, roughfa.ErrInvalidInputChar, c.Put('c'))
+}
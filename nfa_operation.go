@@ -0,0 +1,138 @@
+package roughfa
+
+import "github.com/berquerant/roughfa/internal/set"
+
+const (
+	// operationStartState is the name of the state that Star and Optional add.
+	operationStartState = "start"
+)
+
+// Concat creates a NFAMachine that accepts the concatenations of the words accepted by a and b.
+// The states of a and b are renamed by the prefixes a. and b.,
+// and the accept states of a have the epsilon transitions to the start states of b.
+// The chars are the union of the chars of a and b, or universe if the chars of a or b is universe.
+// The otherwise transitions of a and b are expanded by their own chars before the concatenation,
+// so that the characters out of the chars of a or b are still rejected by them.
+func Concat(a, b NFAMachine) NFAMachine {
+	var (
+		ma          = asNFAMachine(a).expandOtherwise().renamed("a.")
+		mb          = asNFAMachine(b).expandOtherwise().renamed("b.")
+		transitions = make(map[string]map[rune]set.StringSet, len(ma.transitions)+len(mb.transitions))
+		states      = ma.states.Clone()
+		chars       = set.NewRuneSet()
+	)
+	states.Add(mb.states.Unwrap()...)
+	if ma.chars.Len() > 0 && mb.chars.Len() > 0 {
+		chars.Add(ma.chars.Unwrap()...)
+		chars.Add(mb.chars.Unwrap()...)
+		chars.Add(Epsilon)
+	}
+	for fromState, x := range ma.transitions {
+		transitions[fromState] = x
+	}
+	for fromState, x := range mb.transitions {
+		transitions[fromState] = x
+	}
+	for _, state := range ma.acceptStates.Unwrap() {
+		addTransitions(transitions, state, Epsilon, mb.startStates.Unwrap()...)
+	}
+	return &nfaMachine{
+		states:        states,
+		chars:         chars,
+		startStates:   ma.startStates.Clone(),
+		acceptStates:  mb.acceptStates.Clone(),
+		transitions:   transitions,
+		currentStates: ma.startStates.Clone(),
+	}
+}
+
+// Star creates a NFAMachine that accepts zero or more concatenations of the words accepted by a.
+// A new start state is added, that is an accept state and named start, or start followed by primes if start is used.
+// The new start state has the epsilon transitions to the start states of a,
+// and the accept states of a have the epsilon transitions to the new start state.
+func Star(a NFAMachine) NFAMachine {
+	var (
+		m           = asNFAMachine(a)
+		start       = uniqueName(m.states, operationStartState)
+		transitions = m.cloneTransitions()
+		states      = m.states.Clone()
+	)
+	states.Add(start)
+	addTransitions(transitions, start, Epsilon, m.startStates.Unwrap()...)
+	for _, state := range m.acceptStates.Unwrap() {
+		addTransitions(transitions, state, Epsilon, start)
+	}
+	return &nfaMachine{
+		states:        states,
+		chars:         withEpsilon(m.chars),
+		startStates:   set.NewStringSet(start),
+		acceptStates:  set.NewStringSet(start),
+		transitions:   transitions,
+		currentStates: set.NewStringSet(start),
+	}
+}
+
+// Plus creates a NFAMachine that accepts one or more concatenations of the words accepted by a.
+// The accept states of a have the epsilon transitions to the start states of a.
+func Plus(a NFAMachine) NFAMachine {
+	var (
+		m           = asNFAMachine(a)
+		transitions = m.cloneTransitions()
+	)
+	for _, state := range m.acceptStates.Unwrap() {
+		addTransitions(transitions, state, Epsilon, m.startStates.Unwrap()...)
+	}
+	return &nfaMachine{
+		states:        m.states.Clone(),
+		chars:         withEpsilon(m.chars),
+		startStates:   m.startStates.Clone(),
+		acceptStates:  m.acceptStates.Clone(),
+		transitions:   transitions,
+		currentStates: m.startStates.Clone(),
+	}
+}
+
+// Optional creates a NFAMachine that accepts the empty word and the words accepted by a.
+// A new start state is added, that is an accept state without transitions
+// and named start, or start followed by primes if start is used.
+func Optional(a NFAMachine) NFAMachine {
+	var (
+		m           = asNFAMachine(a)
+		start       = uniqueName(m.states, operationStartState)
+		states      = m.states.Clone()
+		startStates = m.startStates.Clone()
+		accepts     = m.acceptStates.Clone()
+	)
+	states.Add(start)
+	startStates.Add(start)
+	accepts.Add(start)
+	return &nfaMachine{
+		states:        states,
+		chars:         m.chars.Clone(),
+		startStates:   startStates,
+		acceptStates:  accepts,
+		transitions:   m.cloneTransitions(),
+		currentStates: startStates.Clone(),
+	}
+}
+
+// withEpsilon returns the chars that have epsilon for the added epsilon transitions,
+// or universe if the chars are universe.
+func withEpsilon(chars set.RuneSet) set.RuneSet {
+	cs := chars.Clone()
+	if cs.Len() > 0 {
+		cs.Add(Epsilon)
+	}
+	return cs
+}
+
+// addTransitions adds the transitions from fromState by c to toStates.
+func addTransitions(transitions map[string]map[rune]set.StringSet, fromState string, c rune, toStates ...string) {
+	if _, ok := transitions[fromState]; !ok {
+		transitions[fromState] = map[rune]set.StringSet{}
+	}
+	if _, ok := transitions[fromState][c]; !ok {
+		transitions[fromState][c] = set.NewStringSet()
+	}
+	transitions[fromState][c].Add(toStates...)
+}
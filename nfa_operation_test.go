@@ -0,0 +1,236 @@
+package roughfa_test
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+// newABStarMachine creates a dfa of (ab)* whose accept state has an outbound transition.
+func newABStarMachine() roughfa.NFAMachine {
+	m, err := roughfa.NewNFAMachineBuilder().
+		States([]string{"0", "1"}).
+		StartStates([]string{"0"}).
+		AcceptStates([]string{"0"}).
+		Transitions(map[string]map[rune][]string{
+			"0": {
+				'a': {"1"},
+			},
+			"1": {
+				'b': {"0"},
+			},
+		}).
+		Build()
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+type nfaOperationTestcase struct {
+	name    string
+	machine func() (roughfa.NFAMachine, error)
+	pattern string
+	chars   string
+}
+
+func (s nfaOperationTestcase) test(t *testing.T) {
+	m, err := s.machine()
+	if !assert.Nil(t, err) {
+		return
+	}
+	d, err := m.ApplyEpsilonExpansion().ApplyPowersetConstruction()
+	if !assert.Nil(t, err) {
+		return
+	}
+	want := regexp.MustCompile(`^(?:` + s.pattern + `)$`)
+	for _, w := range allWords(s.chars, 6) {
+		assert.Equal(t, want.MatchString(w), nfaAccepts(m, w), "%q", w)
+		assert.Equal(t, want.MatchString(w), nfaAccepts(d, w), "powerset %q", w)
+	}
+}
+
+func TestNFAOperation(t *testing.T) {
+	compile := func(pattern string) roughfa.NFAMachine {
+		m, err := roughfa.CompileRegexp(pattern)
+		if err != nil {
+			panic(err)
+		}
+		return m
+	}
+	for _, tc := range []*nfaOperationTestcase{
+		{
+			name: "concat",
+			machine: func() (roughfa.NFAMachine, error) {
+				return roughfa.Concat(newABStarMachine(), compile("a|b")), nil
+			},
+			pattern: "(ab)*(a|b)",
+			chars:   "ab",
+		},
+		{
+			name: "concat itself",
+			machine: func() (roughfa.NFAMachine, error) {
+				return roughfa.Concat(newABStarMachine(), newABStarMachine()), nil
+			},
+			pattern: "(ab)*",
+			chars:   "ab",
+		},
+		{
+			name: "star",
+			machine: func() (roughfa.NFAMachine, error) {
+				return roughfa.Star(compile("ab?")), nil
+			},
+			pattern: "(ab?)*",
+			chars:   "ab",
+		},
+		{
+			name: "star of accepting start",
+			machine: func() (roughfa.NFAMachine, error) {
+				return roughfa.Star(roughfa.Concat(newABStarMachine(), compile("b"))), nil
+			},
+			pattern: "((ab)*b)*",
+			chars:   "ab",
+		},
+		{
+			name: "plus",
+			machine: func() (roughfa.NFAMachine, error) {
+				return roughfa.Plus(compile("a|bb")), nil
+			},
+			pattern: "(a|bb)+",
+			chars:   "ab",
+		},
+		{
+			name: "optional",
+			machine: func() (roughfa.NFAMachine, error) {
+				return roughfa.Optional(compile("ab")), nil
+			},
+			pattern: "(ab)?",
+			chars:   "ab",
+		},
+		{
+			name: "nested",
+			machine: func() (roughfa.NFAMachine, error) {
+				return roughfa.Concat(roughfa.Plus(compile("a")), roughfa.Optional(roughfa.Star(compile("ba")))), nil
+			},
+			pattern: "a+(ba)*",
+			chars:   "ab",
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestConcatRename(t *testing.T) {
+	m := roughfa.Concat(newABStarMachine(), newABStarMachine())
+	states := m.ToShell().States
+	sort.Strings(states)
+	assert.Equal(t, []string{"a.0", "a.1", "b.0", "b.1"}, states)
+}
+
+func TestStarNewState(t *testing.T) {
+	a, err := roughfa.NewNFAMachineBuilder().
+		States([]string{"start"}).
+		StartStates([]string{"start"}).
+		AcceptStates([]string{"start"}).
+		Transitions(map[string]map[rune][]string{
+			"start": {
+				'a': {"start"},
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"start'"}, roughfa.Star(a).ToShell().StartStates)
+}
+
+func TestNFAOperationChars(t *testing.T) {
+	newMachine := func(chars []rune, transitions map[string]map[rune][]string) roughfa.NFAMachine {
+		m, err := roughfa.NewNFAMachineBuilder().
+			States([]string{"p", "q"}).
+			Chars(chars).
+			StartStates([]string{"p"}).
+			AcceptStates([]string{"q"}).
+			Transitions(transitions).
+			Build()
+		if err != nil {
+			panic(err)
+		}
+		return m
+	}
+	a := newMachine([]rune("a"), map[string]map[rune][]string{
+		"p": {
+			'a': {"q"},
+		},
+	})
+	b := newMachine([]rune("b"), map[string]map[rune][]string{
+		"p": {
+			'b': {"q"},
+		},
+	})
+	bStar, err := roughfa.CompileRegexp("b*")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	t.Run("shell", func(t *testing.T) {
+		for _, tc := range []struct {
+			title   string
+			machine roughfa.NFAMachine
+		}{
+			{
+				title:   "concat",
+				machine: roughfa.Concat(a, b),
+			},
+			{
+				title:   "star",
+				machine: roughfa.Star(a),
+			},
+			{
+				title:   "plus",
+				machine: roughfa.Plus(a),
+			},
+		} {
+			tc := tc
+			t.Run(tc.title, func(t *testing.T) {
+				restored, err := tc.machine.ToShell().ToMachine()
+				if !assert.Nil(t, err) {
+					return
+				}
+				b, err := tc.machine.ToShell().ToJSON()
+				if !assert.Nil(t, err) {
+					return
+				}
+				s, err := roughfa.NewNFAMachineShellFromJSON(b)
+				if !assert.Nil(t, err) {
+					return
+				}
+				fromJSON, err := s.ToMachine()
+				if !assert.Nil(t, err) {
+					return
+				}
+				for _, w := range allWords("ab", 4) {
+					assert.Equal(t, tc.machine.Accepts(w), restored.Accepts(w), "%q", w)
+					assert.Equal(t, tc.machine.Accepts(w), fromJSON.Accepts(w), "json %q", w)
+				}
+			})
+		}
+	})
+
+	t.Run("otherwise", func(t *testing.T) {
+		a2 := newMachine([]rune("a"), map[string]map[rune][]string{
+			"p": {
+				'a':               {"q"},
+				roughfa.Otherwise: {"q"},
+			},
+		})
+		assert.False(t, a2.Accepts("z"))
+		m := roughfa.Concat(a2, bStar)
+		assert.True(t, m.Accepts("ab"))
+		assert.False(t, m.Accepts("zb"))
+		assert.False(t, m.Accepts("z"))
+	})
+}
@@ -0,0 +1,240 @@
+package roughfa
+
+import (
+	"fmt"
+
+	"github.com/berquerant/roughfa/internal/set"
+)
+
+const (
+	// productSinkState is the name of the missing state in the names of the product states.
+	productSinkState = "∅"
+)
+
+type (
+	productState struct {
+		x string
+		y string
+	}
+	// productOperand is a determinized machine that rejects the characters out of the chars of the original machine.
+	// The empty state means the sink state.
+	productOperand struct {
+		m     *nfaMachine
+		chars set.RuneSet
+	}
+)
+
+// Union creates a NFAMachine that accepts the words accepted by a or b.
+// See productMachine for the alphabet and the names of the states.
+func Union(a, b NFAMachine) NFAMachine {
+	return productMachine(a, b, func(x, y bool) bool { return x || y })
+}
+
+// Intersection creates a NFAMachine that accepts the words accepted by both a and b.
+// See productMachine for the alphabet and the names of the states.
+func Intersection(a, b NFAMachine) NFAMachine {
+	return productMachine(a, b, func(x, y bool) bool { return x && y })
+}
+
+// Difference creates a NFAMachine that accepts the words accepted by a but not by b.
+// See productMachine for the alphabet and the names of the states.
+func Difference(a, b NFAMachine) NFAMachine {
+	return productMachine(a, b, func(x, y bool) bool { return x && !y })
+}
+
+// SymmetricDifference creates a NFAMachine that accepts the words accepted by exactly one of a and b.
+// See productMachine for the alphabet and the names of the states.
+func SymmetricDifference(a, b NFAMachine) NFAMachine {
+	return productMachine(a, b, func(x, y bool) bool { return x != y })
+}
+
+// UnionDFA is Union for DFAMachine.
+func UnionDFA(a, b DFAMachine) (DFAMachine, error) { return Union(FromDFA(a), FromDFA(b)).ToDFA() }
+
+// IntersectionDFA is Intersection for DFAMachine.
+func IntersectionDFA(a, b DFAMachine) (DFAMachine, error) {
+	return Intersection(FromDFA(a), FromDFA(b)).ToDFA()
+}
+
+// DifferenceDFA is Difference for DFAMachine.
+func DifferenceDFA(a, b DFAMachine) (DFAMachine, error) {
+	return Difference(FromDFA(a), FromDFA(b)).ToDFA()
+}
+
+// SymmetricDifferenceDFA is SymmetricDifference for DFAMachine.
+func SymmetricDifferenceDFA(a, b DFAMachine) (DFAMachine, error) {
+	return SymmetricDifference(FromDFA(a), FromDFA(b)).ToDFA()
+}
+
+// productMachine creates a dfa that runs a and b simultaneously,
+// and accepts a word if accept returns true for the acceptance of a and b.
+// accept(false, false) must be false.
+//
+// a and b are determinized unless they are dfas.
+// The state of the product is named (x,y) by the state x of a and the state y of b,
+// y is ∅ if b has no transitions, and primes are appended if the name is duplicated.
+//
+// The chars of the product are the union of the chars of a and b,
+// or universe if the chars of a or b is universe.
+// A character that is not in the chars of a is rejected by a, and b likewise.
+// The states that cannot reach an accept state are excluded except the start state.
+func productMachine(a, b NFAMachine, accept func(x, y bool) bool) *nfaMachine {
+	var (
+		pa, pb   = newProductOperands(a, b)
+		alphabet = pa.m.alphabet()
+		chars    = set.NewRuneSet()
+	)
+	alphabet.Add(pb.m.alphabet().Unwrap()...)
+	if pa.chars.Len() > 0 && pb.chars.Len() > 0 {
+		chars.Add(pa.chars.Unwrap()...)
+		chars.Add(pb.chars.Unwrap()...)
+	}
+
+	var (
+		names       = map[productState]string{}
+		used        = set.NewStringSet()
+		states      = set.NewStringSet()
+		transitions = map[string]map[rune]set.StringSet{}
+		accepts     = set.NewStringSet()
+		nameOf      = func(p productState) string {
+			if x, ok := names[p]; ok {
+				return x
+			}
+			x, y := p.x, p.y
+			if x == "" {
+				x = productSinkState
+			}
+			if y == "" {
+				y = productSinkState
+			}
+			name := uniqueName(used, fmt.Sprintf("(%s,%s)", x, y))
+			used.Add(name)
+			names[p] = name
+			return name
+		}
+		start = productState{
+			x: pa.start(),
+			y: pb.start(),
+		}
+		q = []productState{start}
+	)
+	states.Add(nameOf(start))
+	for len(q) > 0 {
+		p := q[0]
+		q = q[1:]
+		from := nameOf(p)
+		if accept(pa.accepts(p.x), pb.accepts(p.y)) {
+			accepts.Add(from)
+		}
+		for _, c := range sortedRunes(alphabet) {
+			next := productState{
+				x: pa.next(p.x, c),
+				y: pb.next(p.y, c),
+			}
+			if next.x == "" && next.y == "" {
+				// rejects any words
+				continue
+			}
+			to := nameOf(next)
+			if !states.In(to) {
+				states.Add(to)
+				q = append(q, next)
+			}
+			addTransitions(transitions, from, c, to)
+		}
+	}
+
+	startState := nameOf(start)
+	m := &nfaMachine{
+		states:        states,
+		chars:         chars,
+		startStates:   set.NewStringSet(startState),
+		acceptStates:  accepts,
+		transitions:   transitions,
+		currentStates: set.NewStringSet(startState),
+	}
+	return m.trimDeadStates()
+}
+
+// shortestProductWord returns a shortest word that accept returns true for the acceptance of a and b.
+// accept(false, false) must be false.
+// Returns false if no such words.
+func shortestProductWord(a, b *productOperand, accept func(x, y bool) bool) (string, bool) {
+	type node struct {
+		state  productState
+		parent *node
+		char   rune
+	}
+	var (
+		alphabet = a.m.alphabet()
+		start    = productState{
+			x: a.start(),
+			y: b.start(),
+		}
+		visited = map[productState]bool{start: true}
+		q       = []*node{{state: start}}
+	)
+	alphabet.Add(b.m.alphabet().Unwrap()...)
+	chars := sortedRunes(alphabet)
+	for len(q) > 0 {
+		n := q[0]
+		q = q[1:]
+		if accept(a.accepts(n.state.x), b.accepts(n.state.y)) {
+			var word []rune
+			for x := n; x.parent != nil; x = x.parent {
+				word = append(word, x.char)
+			}
+			for i, j := 0, len(word)-1; i < j; i, j = i+1, j-1 {
+				word[i], word[j] = word[j], word[i]
+			}
+			return string(word), true
+		}
+		for _, c := range chars {
+			next := productState{
+				x: a.next(n.state.x, c),
+				y: b.next(n.state.y, c),
+			}
+			if next.x == "" && next.y == "" || visited[next] {
+				continue
+			}
+			visited[next] = true
+			q = append(q, &node{
+				state:  next,
+				parent: n,
+				char:   c,
+			})
+		}
+	}
+	return "", false
+}
+
+// newProductOperands creates the operands of a and b.
+// The otherwise transitions of a and b are expanded so that they are by the same characters.
+func newProductOperands(a, b NFAMachine) (*productOperand, *productOperand) {
+	var (
+		ma    = asNFAMachine(a)
+		mb    = asNFAMachine(b)
+		chars = ma.explicitChars()
+	)
+	chars.Add(mb.explicitChars().Unwrap()...)
+	return newProductOperand(ma.expandOtherwiseBy(chars)), newProductOperand(mb.expandOtherwiseBy(chars))
+}
+
+func newProductOperand(m *nfaMachine) *productOperand {
+	return &productOperand{
+		m:     m.determinize(),
+		chars: m.chars,
+	}
+}
+
+func (s productOperand) start() string { return s.m.startStates.Unwrap()[0] }
+func (s productOperand) accepts(state string) bool {
+	return state != "" && s.m.acceptStates.In(state)
+}
+func (s productOperand) next(state string, c rune) string {
+	if state == "" || s.chars.Len() > 0 && !s.chars.In(c) {
+		return ""
+	}
+	x, _ := s.m.dfaNext(state, c)
+	return x
+}
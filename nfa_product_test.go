@@ -0,0 +1,195 @@
+package roughfa_test
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+type productTestcase struct {
+	name     string
+	patternA string
+	patternB string
+	chars    string
+}
+
+func (s productTestcase) test(t *testing.T) {
+	a, err := roughfa.CompileRegexp(s.patternA)
+	if !assert.Nil(t, err) {
+		return
+	}
+	b, err := roughfa.CompileRegexp(s.patternB)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var (
+		ra = regexp.MustCompile(`^(?:` + s.patternA + `)$`)
+		rb = regexp.MustCompile(`^(?:` + s.patternB + `)$`)
+	)
+	for _, op := range []struct {
+		name string
+		f    func(a, b roughfa.NFAMachine) roughfa.NFAMachine
+		want func(x, y bool) bool
+	}{
+		{
+			name: "union",
+			f:    roughfa.Union,
+			want: func(x, y bool) bool { return x || y },
+		},
+		{
+			name: "intersection",
+			f:    roughfa.Intersection,
+			want: func(x, y bool) bool { return x && y },
+		},
+		{
+			name: "difference",
+			f:    roughfa.Difference,
+			want: func(x, y bool) bool { return x && !y },
+		},
+		{
+			name: "symmetric difference",
+			f:    roughfa.SymmetricDifference,
+			want: func(x, y bool) bool { return x != y },
+		},
+	} {
+		m := op.f(a, b)
+		assert.True(t, m.IsDFA(), op.name)
+		for _, w := range allWords(s.chars, 5) {
+			assert.Equal(t, op.want(ra.MatchString(w), rb.MatchString(w)), nfaAccepts(m, w), "%s %q", op.name, w)
+		}
+	}
+}
+
+func TestProduct(t *testing.T) {
+	for _, tc := range []*productTestcase{
+		{
+			name:     "disjoint",
+			patternA: "a*",
+			patternB: "b*",
+			chars:    "ab",
+		},
+		{
+			name:     "overlap",
+			patternA: "(a|b)*a",
+			patternB: "a(a|b)*",
+			chars:    "ab",
+		},
+		{
+			name:     "different alphabets",
+			patternA: "(ab)*",
+			patternB: "(a|c)+b?",
+			chars:    "abcd",
+		},
+		{
+			name:     "empty",
+			patternA: "",
+			patternB: "a?",
+			chars:    "a",
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestProductChars(t *testing.T) {
+	a, err := roughfa.NewNFAMachineBuilder().
+		States([]string{"0"}).
+		Chars([]rune{'a'}).
+		StartStates([]string{"0"}).
+		AcceptStates([]string{"0"}).
+		Transitions(map[string]map[rune][]string{
+			"0": {
+				'a': {"0"},
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	b, err := roughfa.NewNFAMachineBuilder().
+		States([]string{"0"}).
+		Chars([]rune{'b'}).
+		StartStates([]string{"0"}).
+		AcceptStates([]string{"0"}).
+		Transitions(map[string]map[rune][]string{
+			"0": {
+				'b': {"0"},
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	u := roughfa.Union(a, b)
+	chars := u.ToShell().Chars
+	sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+	assert.Equal(t, []rune{'a', 'b'}, chars)
+	assert.True(t, nfaAccepts(u, "aa"))
+	assert.True(t, nfaAccepts(u, "bb"))
+	assert.False(t, nfaAccepts(u, "ab"))
+	assert.Equal(t, roughfa.ErrInvalidInputChar, u.Put('c'))
+
+	c, err := roughfa.CompileRegexp("(a|b|c)*")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 0, len(roughfa.Union(a, c).ToShell().Chars), "universe")
+}
+
+func TestProductDFA(t *testing.T) {
+	// parity accepts the words that have even c, over c and d.
+	parity := func(c, d rune) roughfa.DFAMachine {
+		m, err := roughfa.NewDFAMachineBuilder().
+			States([]string{"even", "odd"}).
+			StartState("even").
+			AcceptStates([]string{"even"}).
+			Transitions(map[string]map[rune]string{
+				"even": {
+					c: "odd",
+					d: "even",
+				},
+				"odd": {
+					c: "even",
+					d: "odd",
+				},
+			}).
+			Build()
+		if err != nil {
+			panic(err)
+		}
+		return m
+	}
+	m, err := roughfa.IntersectionDFA(parity('a', 'b'), parity('a', 'b'))
+	if !assert.Nil(t, err) {
+		return
+	}
+	states := m.ToShell().States
+	sort.Strings(states)
+	assert.Equal(t, []string{"(even,even)", "(odd,odd)"}, states)
+	assert.Equal(t, "(even,even)", m.State())
+	assert.True(t, m.IsAccepted())
+
+	m, err = roughfa.SymmetricDifferenceDFA(parity('a', 'b'), parity('b', 'a'))
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, x := range []struct {
+		input string
+		want  bool
+	}{
+		{input: "", want: false},
+		{input: "a", want: true},
+		{input: "ab", want: false},
+		{input: "abb", want: true},
+		{input: "aabb", want: false},
+	} {
+		m.Reset()
+		for _, c := range x.input {
+			assert.Nil(t, m.Put(c), x.input)
+		}
+		assert.Equal(t, x.want, m.IsAccepted(), x.input)
+	}
+}
@@ -0,0 +1,161 @@
+package roughfa
+
+import "sort"
+
+type (
+	// stateEliminator is a generalized nfa whose transitions are labeled by regular expressions.
+	stateEliminator struct {
+		// edges[p][q] is the expression of the transitions from p to q.
+		edges map[int]map[int]*Regexp
+		// remaining is the states that are not eliminated yet.
+		remaining map[int]bool
+	}
+)
+
+const (
+	eliminatorStartState = iota
+	eliminatorAcceptState
+	eliminatorFirstState
+)
+
+// ToRegexp creates a regular expression that matches the language of m by the state elimination.
+// The states are eliminated in the order that the expression grows least,
+// and the result is simplified by SimplifyRegexp.
+// The result is EmptySetRegexpOp if m accepts nothing.
+// Use FromDFA to convert a DFAMachine.
+//
+// The otherwise transitions are expanded into the transitions by the chars.
+// Returns ErrOtherwiseExists if the chars are universe and an otherwise transition is necessary,
+// because a regular expression cannot match the characters that appear in no transitions.
+func ToRegexp(m NFAMachine) (*Regexp, error) {
+	var (
+		s      = asNFAMachine(m).expandOtherwise()
+		useful = s.reachableStates().And(s.coReachableStates()).Unwrap()
+		ids    = make(map[string]int, len(useful))
+		e      = &stateEliminator{
+			edges:     map[int]map[int]*Regexp{},
+			remaining: map[int]bool{},
+		}
+	)
+	sort.Strings(useful)
+	for i, state := range useful {
+		ids[state] = eliminatorFirstState + i
+		e.remaining[eliminatorFirstState+i] = true
+	}
+	for _, state := range useful {
+		if s.startStates.In(state) {
+			e.add(eliminatorStartState, ids[state], newEmptyRegexp())
+		}
+		if s.acceptStates.In(state) {
+			e.add(ids[state], eliminatorAcceptState, newEmptyRegexp())
+		}
+		for c, toStates := range s.transitions[state] {
+			label := newCharRegexp(c)
+			if c == Epsilon {
+				label = newEmptyRegexp()
+			}
+			for _, toState := range toStates.Unwrap() {
+				id, ok := ids[toState]
+				if !ok {
+					continue
+				}
+				if c == Otherwise {
+					return nil, ErrOtherwiseExists
+				}
+				e.add(ids[state], id, label)
+			}
+		}
+	}
+	for len(e.remaining) > 0 {
+		e.eliminate(e.next())
+	}
+	if re, ok := e.edges[eliminatorStartState][eliminatorAcceptState]; ok {
+		return SimplifyRegexp(re), nil
+	}
+	return newEmptySetRegexp(), nil
+}
+
+func (s *stateEliminator) add(p, q int, re *Regexp) {
+	if _, ok := s.edges[p]; !ok {
+		s.edges[p] = map[int]*Regexp{}
+	}
+	if x, ok := s.edges[p][q]; ok {
+		s.edges[p][q] = newAlternateRegexp(x, re)
+		return
+	}
+	s.edges[p][q] = re
+}
+
+// neighbors returns the predecessors and the successors of k except k.
+func (s *stateEliminator) neighbors(k int) ([]int, []int) {
+	var preds, succs []int
+	for p, x := range s.edges {
+		if _, ok := x[k]; ok && p != k {
+			preds = append(preds, p)
+		}
+	}
+	for q := range s.edges[k] {
+		if q != k {
+			succs = append(succs, q)
+		}
+	}
+	sort.Ints(preds)
+	sort.Ints(succs)
+	return preds, succs
+}
+
+// weight estimates how much the expressions grow by eliminating k.
+func (s *stateEliminator) weight(k int) int {
+	var (
+		preds, succs = s.neighbors(k)
+		w            int
+	)
+	for _, p := range preds {
+		w += regexpSize(s.edges[p][k]) * (len(succs) - 1)
+	}
+	for _, q := range succs {
+		w += regexpSize(s.edges[k][q]) * (len(preds) - 1)
+	}
+	if loop, ok := s.edges[k][k]; ok {
+		w += regexpSize(loop) * (len(preds)*len(succs) - 1)
+	}
+	return w
+}
+
+// next selects the state to be eliminated.
+func (s *stateEliminator) next() int {
+	ks := make([]int, 0, len(s.remaining))
+	for k := range s.remaining {
+		ks = append(ks, k)
+	}
+	sort.Ints(ks)
+	var (
+		best       = ks[0]
+		bestWeight = s.weight(best)
+	)
+	for _, k := range ks[1:] {
+		if w := s.weight(k); w < bestWeight {
+			best = k
+			bestWeight = w
+		}
+	}
+	return best
+}
+
+func (s *stateEliminator) eliminate(k int) {
+	preds, succs := s.neighbors(k)
+	loop := newEmptyRegexp()
+	if x, ok := s.edges[k][k]; ok {
+		loop = newStarRegexp(x)
+	}
+	for _, p := range preds {
+		for _, q := range succs {
+			s.add(p, q, newConcatRegexp(s.edges[p][k], loop, s.edges[k][q]))
+		}
+	}
+	for _, p := range preds {
+		delete(s.edges[p], k)
+	}
+	delete(s.edges, k)
+	delete(s.remaining, k)
+}
@@ -0,0 +1,199 @@
+package roughfa_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+type toRegexpTestcase struct {
+	name    string
+	pattern string
+	chars   string
+	want    string
+}
+
+func (s toRegexpTestcase) test(t *testing.T) {
+	m, err := roughfa.CompileRegexp(s.pattern)
+	if !assert.Nil(t, err) {
+		return
+	}
+	re, err := roughfa.ToRegexp(m)
+	if !assert.Nil(t, err) {
+		return
+	}
+	got := re.String()
+	t.Logf("%s => %s", s.pattern, got)
+	if s.want != "" {
+		assert.Equal(t, s.want, got)
+	}
+	r, err := roughfa.CompileRegexp(got)
+	if !assert.Nil(t, err) {
+		return
+	}
+	want := regexp.MustCompile(`^(?:` + s.pattern + `)$`)
+	for _, w := range allWords(s.chars, 5) {
+		assert.Equal(t, want.MatchString(w), nfaAccepts(r, w), "%q", w)
+	}
+}
+
+func TestToRegexp(t *testing.T) {
+	for _, tc := range []*toRegexpTestcase{
+		{
+			name:  "empty",
+			chars: "a",
+			want:  "()",
+		},
+		{
+			name:    "char",
+			pattern: "a",
+			chars:   "ab",
+			want:    "a",
+		},
+		{
+			name:    "concat",
+			pattern: "abc",
+			chars:   "abc",
+			want:    "abc",
+		},
+		{
+			name:    "alternate",
+			pattern: "b|a|c",
+			chars:   "abc",
+			want:    "a|b|c",
+		},
+		{
+			name:    "star",
+			pattern: "a*",
+			chars:   "ab",
+			want:    "a*",
+		},
+		{
+			name:    "common prefix",
+			pattern: "ab|ac",
+			chars:   "abc",
+			want:    "a(b|c)",
+		},
+		{
+			name:    "(a|b)*a",
+			pattern: "(a|b)*a",
+			chars:   "ab",
+		},
+		{
+			name:    "a(b|c)*d",
+			pattern: "a(b|c)*d",
+			chars:   "abcd",
+		},
+		{
+			name:    "(a*)*b+",
+			pattern: "(a*)*b+",
+			chars:   "ab",
+		},
+		{
+			name:    "(ab|ba)*c?",
+			pattern: "(ab|ba)*c?",
+			chars:   "abc",
+		},
+		{
+			name:    "meta chars",
+			pattern: `\(\|\)*`,
+			chars:   "(|)",
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestToRegexpFromDFA(t *testing.T) {
+	m, err := roughfa.NewDFAMachineBuilder().
+		States([]string{"even", "odd"}).
+		StartState("even").
+		AcceptStates([]string{"odd"}).
+		Transitions(map[string]map[rune]string{
+			"even": {
+				'0': "even",
+				'1': "odd",
+			},
+			"odd": {
+				'0': "odd",
+				'1': "even",
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	re, err := roughfa.ToRegexp(roughfa.FromDFA(m))
+	if !assert.Nil(t, err) {
+		return
+	}
+	got := re.String()
+	t.Log(got)
+	r, err := roughfa.CompileRegexp(got)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, w := range allWords("01", 6) {
+		var ones int
+		for _, c := range w {
+			if c == '1' {
+				ones++
+			}
+		}
+		assert.Equal(t, ones%2 == 1, nfaAccepts(r, w), "%q", w)
+	}
+}
+
+func TestToRegexpEmptySet(t *testing.T) {
+	m, err := roughfa.NewNFAMachineBuilder().
+		States([]string{"0", "1"}).
+		StartStates([]string{"0"}).
+		AcceptStates([]string{"1"}).
+		Transitions(map[string]map[rune][]string{
+			"1": {
+				'a': {"0"},
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	got, err := roughfa.ToRegexp(m)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, roughfa.EmptySetRegexpOp, got.Op)
+	assert.Equal(t, "∅", got.String())
+}
+
+func TestToRegexpOtherwise(t *testing.T) {
+	t.Run("universe", func(t *testing.T) {
+		m, err := newCommentMachine(nil)
+		if !assert.Nil(t, err) {
+			return
+		}
+		_, err = roughfa.ToRegexp(roughfa.FromDFA(m))
+		assert.Equal(t, roughfa.ErrOtherwiseExists, err)
+	})
+	t.Run("chars", func(t *testing.T) {
+		m, err := newCommentMachine([]rune{'/', '\n', 'x', roughfa.Otherwise})
+		if !assert.Nil(t, err) {
+			return
+		}
+		re, err := roughfa.ToRegexp(roughfa.FromDFA(m))
+		if !assert.Nil(t, err) {
+			return
+		}
+		t.Log(re)
+		r, err := roughfa.CompileRegexp(re.String())
+		if !assert.Nil(t, err) {
+			return
+		}
+		for _, w := range allWords("/\nx", 5) {
+			assert.Equal(t, m.Accepts(w), nfaAccepts(r, w), "%q", w)
+		}
+		assert.False(t, nfaAccepts(r, string(roughfa.Otherwise)))
+	})
+}
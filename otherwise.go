@@ -0,0 +1,193 @@
+package roughfa
+
+import "github.com/berquerant/roughfa/internal/set"
+
+const (
+	// Otherwise is for the otherwise transition, the transition by the characters
+	// that have no transitions from the state.
+	// Otherwise is a noncharacter, U+FFFF.
+	//
+	// Determinization, completion, minimization and the analyses treat Otherwise as a character
+	// that stands for any character that appears in no transitions of the machine,
+	// so a word returned by them may contain Otherwise.
+	// They may add a dead state so that the otherwise transitions are not taken by the other characters.
+	Otherwise = '\uFFFF'
+)
+
+// explicitChars returns the chars, or the chars of the transitions except epsilon and otherwise
+// if the chars are universe.
+func (s nfaMachine) explicitChars() set.RuneSet {
+	cs := s.alphabet()
+	cs.Del(Otherwise)
+	return cs
+}
+
+func (s nfaMachine) hasOtherwise() bool {
+	for _, x := range s.transitions {
+		if _, ok := x[Otherwise]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// expandOtherwise creates a nfaMachine whose otherwise transitions are expanded
+// into the transitions by the explicit chars.
+// The remaining otherwise transitions are by the characters that appear in no transitions,
+// and are removed if the chars are not universe.
+func (s nfaMachine) expandOtherwise() *nfaMachine { return s.expandOtherwiseBy(s.explicitChars()) }
+
+// expandOtherwiseBy is expandOtherwise by the chars instead of the explicit chars,
+// except that the chars of this are used if they are not universe.
+func (s nfaMachine) expandOtherwiseBy(chars set.RuneSet) *nfaMachine {
+	if !s.hasOtherwise() {
+		return &s
+	}
+	if s.chars.Len() > 0 {
+		chars = s.chars
+	}
+	transitions := s.cloneTransitions()
+	for _, x := range transitions {
+		toStates, ok := x[Otherwise]
+		if !ok {
+			continue
+		}
+		for _, c := range chars.Unwrap() {
+			if _, ok := x[c]; !ok && c != Epsilon {
+				x[c] = toStates.Clone()
+			}
+		}
+		if s.chars.Len() > 0 {
+			delete(x, Otherwise)
+		}
+	}
+	return &nfaMachine{
+		states:        s.states.Clone(),
+		chars:         s.chars.Clone(),
+		startStates:   s.startStates.Clone(),
+		acceptStates:  s.acceptStates.Clone(),
+		transitions:   transitions,
+		currentStates: s.currentStates.Clone(),
+	}
+}
+
+// move returns the destinations of the transitions from the state by c,
+// or of the otherwise transition if no transitions by c.
+func (s nfaMachine) move(state string, c rune) (set.StringSet, bool) {
+	t, ok := s.transitions[state]
+	if !ok {
+		return nil, false
+	}
+	if x, ok := t[c]; ok {
+		return x, true
+	}
+	if c == Epsilon {
+		return nil, false
+	}
+	x, ok := t[Otherwise]
+	return x, ok
+}
+
+func (s dfaMachine) hasOtherwise() bool {
+	for _, x := range s.transitions {
+		if _, ok := x[Otherwise]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// expandOtherwise creates a dfaMachine whose otherwise transitions are expanded
+// into the transitions by the explicit chars.
+// See nfaMachine.expandOtherwise.
+func (s dfaMachine) expandOtherwise() *dfaMachine {
+	if !s.hasOtherwise() {
+		return &s
+	}
+	chars := s.alphabet()
+	chars.Del(Otherwise)
+	transitions := s.cloneTransitions()
+	for _, x := range transitions {
+		toState, ok := x[Otherwise]
+		if !ok {
+			continue
+		}
+		for _, c := range chars.Unwrap() {
+			if _, ok := x[c]; !ok {
+				x[c] = toState
+			}
+		}
+		if s.chars.Len() > 0 {
+			delete(x, Otherwise)
+		}
+	}
+	return &dfaMachine{
+		states:       s.states.Clone(),
+		chars:        s.chars.Clone(),
+		startState:   s.startState,
+		acceptStates: s.acceptStates.Clone(),
+		transitions:  transitions,
+		currentState: s.currentState,
+	}
+}
+
+// move returns the destination of the transition from the state by c,
+// or of the otherwise transition if no transitions by c.
+func (s dfaMachine) move(state string, c rune) (string, bool) {
+	t, ok := s.transitions[state]
+	if !ok {
+		return "", false
+	}
+	if x, ok := t[c]; ok {
+		return x, true
+	}
+	x, ok := t[Otherwise]
+	return x, ok
+}
+
+// completedChars returns the chars of the completed machine.
+// The chars remain universe if the alphabet has otherwise, because otherwise stands for the rest of the characters.
+func completedChars(chars, alphabet set.RuneSet) set.RuneSet {
+	if chars.Len() == 0 && alphabet.In(Otherwise) {
+		return set.NewRuneSet()
+	}
+	return alphabet
+}
+
+// blockOtherwise adds the transitions to a dead state by the chars
+// that have no transitions from the states that have the otherwise transitions,
+// so that the otherwise transitions are not taken by the chars.
+// The constructions that treat otherwise as a character need this
+// because they may remove the transitions by the chars from such states.
+func (s *nfaMachine) blockOtherwise(chars set.RuneSet) *nfaMachine {
+	sink := uniqueName(s.states, sinkState)
+	for _, x := range s.transitions {
+		if _, ok := x[Otherwise]; !ok {
+			continue
+		}
+		for _, c := range chars.Unwrap() {
+			if _, ok := x[c]; !ok && c != Otherwise && c != Epsilon {
+				x[c] = set.NewStringSet(sink)
+				s.states.Add(sink)
+			}
+		}
+	}
+	return s
+}
+
+// blockOtherwise is nfaMachine.blockOtherwise for dfaMachine.
+func (s *dfaMachine) blockOtherwise(chars set.RuneSet) *dfaMachine {
+	sink := uniqueName(s.states, sinkState)
+	for _, x := range s.transitions {
+		if _, ok := x[Otherwise]; !ok {
+			continue
+		}
+		for _, c := range chars.Unwrap() {
+			if _, ok := x[c]; !ok && c != Otherwise {
+				x[c] = sink
+				s.states.Add(sink)
+			}
+		}
+	}
+	return s
+}
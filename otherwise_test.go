@@ -0,0 +1,221 @@
+package roughfa_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCommentMachine creates a dfa that accepts the words whose last line has no //.
+func newCommentMachine(chars []rune) (roughfa.DFAMachine, error) {
+	return roughfa.NewDFAMachineBuilder().
+		States([]string{"code", "slash", "comment"}).
+		Chars(chars).
+		StartState("code").
+		AcceptStates([]string{"code", "slash"}).
+		Transitions(map[string]map[rune]string{
+			"code": {
+				'/':               "slash",
+				roughfa.Otherwise: "code",
+			},
+			"slash": {
+				'/':               "comment",
+				roughfa.Otherwise: "code",
+			},
+			"comment": {
+				'\n':              "code",
+				roughfa.Otherwise: "comment",
+			},
+		}).
+		Build()
+}
+
+func commentAccepts(input string) bool {
+	lines := strings.Split(input, "\n")
+	return !strings.Contains(lines[len(lines)-1], "//")
+}
+
+func TestOtherwiseDFA(t *testing.T) {
+	m, err := newCommentMachine(nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, w := range allWords("/\nx", 5) {
+		assert.Equal(t, commentAccepts(w), m.Accepts(w), "%q", w)
+	}
+	assert.True(t, m.Accepts("x = 1 / 2 // あ\nok"))
+
+	b, err := m.ToShell().ToJSON()
+	if !assert.Nil(t, err) {
+		return
+	}
+	shell, err := roughfa.NewDFAMachineShellFromJSON(b)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "code", shell.Transitions["code"][roughfa.Otherwise])
+	restored, err := shell.ToMachine()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.False(t, restored.Accepts("a//b"))
+
+	d, err := m.ToDot()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, strings.Contains(d.AsDot(), `label="else"`), d.AsDot())
+
+	// code and slash are not equivalent, otherwise the machine is minimal
+	min, mapping := m.Minimize()
+	assert.Equal(t, 3, len(min.ToShell().States))
+	assert.Equal(t, 3, len(mapping))
+	ok, w := roughfa.EquivalentDFA(m, min)
+	assert.True(t, ok, "distinguished by %q", w)
+	for _, w := range allWords("/\nx", 5) {
+		assert.Equal(t, commentAccepts(w), min.Accepts(w), "minimal %q", w)
+	}
+
+	c := roughfa.FromDFA(m).Complement()
+	for _, w := range allWords("/\nx", 5) {
+		assert.Equal(t, !commentAccepts(w), c.Accepts(w), "complement %q", w)
+	}
+}
+
+func TestOtherwiseChars(t *testing.T) {
+	m, err := newCommentMachine([]rune{'/', '\n', 'x', 'y'})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, m.Accepts("x/y"))
+	assert.False(t, m.Accepts("x//y"))
+	assert.False(t, m.Accepts("z"))
+	m.Reset()
+	assert.Equal(t, roughfa.ErrInvalidInputChar, m.Put('z'))
+
+	c := m.Complete()
+	assert.Equal(t, 3, len(c.ToShell().States))
+	for _, x := range c.ToShell().Transitions {
+		_, ok := x[roughfa.Otherwise]
+		assert.False(t, ok, "expanded into the chars")
+		assert.Equal(t, 4, len(x))
+	}
+}
+
+func TestOtherwiseNFA(t *testing.T) {
+	// accepts the words whose last character is not a, or a word ab
+	m, err := roughfa.NewNFAMachineBuilder().
+		States([]string{"0", "1", "2", "3"}).
+		StartStates([]string{"0"}).
+		AcceptStates([]string{"2"}).
+		Transitions(map[string]map[rune][]string{
+			"0": {
+				roughfa.Epsilon:   {"1"},
+				'a':               {"3"},
+				roughfa.Otherwise: {"0"},
+			},
+			"1": {
+				'a':               {"0"},
+				roughfa.Otherwise: {"0", "2"},
+			},
+			"3": {
+				'b': {"2"},
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	accept := func(w string) bool {
+		return w == "ab" || w != "" && !strings.HasSuffix(w, "a")
+	}
+	var (
+		d = m.Determinize(roughfa.NumberPowersetNaming).Machine
+		c = m.Complement()
+	)
+	min, err := m.Minimize()
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, w := range allWords("abz", 5) {
+		assert.Equal(t, accept(w), m.Accepts(w), "%q", w)
+		assert.Equal(t, accept(w), nfaAccepts(m, w), "put %q", w)
+		assert.Equal(t, accept(w), d.Accepts(w), "dfa %q", w)
+		assert.Equal(t, accept(w), min.Accepts(w), "minimal %q", w)
+		assert.Equal(t, !accept(w), c.Accepts(w), "complement %q", w)
+	}
+	u, w := roughfa.FromDFA(d).IsUniversal()
+	assert.False(t, u)
+	assert.Equal(t, "", w)
+
+	b, err := m.ToShell().ToJSON()
+	if !assert.Nil(t, err) {
+		return
+	}
+	shell, err := roughfa.NewNFAMachineShellFromJSON(b)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.ElementsMatch(t, []string{"0", "2"}, shell.Transitions["1"][roughfa.Otherwise])
+}
+
+func TestOtherwiseTrim(t *testing.T) {
+	// accepts any character except a
+	m, err := roughfa.NewDFAMachineBuilder().
+		States([]string{"0", "1", "dead"}).
+		StartState("0").
+		AcceptStates([]string{"1"}).
+		Transitions(map[string]map[rune]string{
+			"0": {
+				'a':               "dead",
+				roughfa.Otherwise: "1",
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	min, _ := m.Minimize()
+	for _, x := range []roughfa.DFAMachine{m, m.Trim(), min} {
+		assert.False(t, x.Accepts("a"))
+		assert.True(t, x.Accepts("b"))
+		assert.False(t, x.Accepts("bb"))
+	}
+	n := roughfa.FromDFA(m).Trim()
+	assert.False(t, n.Accepts("a"))
+	assert.True(t, n.Accepts("b"))
+
+	t.Run("reached by otherwise", func(t *testing.T) {
+		// accepts a or the words of b following any character except a
+		m, err := roughfa.NewDFAMachineBuilder().
+			States([]string{"s", "f"}).
+			StartState("s").
+			AcceptStates([]string{"s", "f"}).
+			Transitions(map[string]map[rune]string{
+				"s": {
+					'a':               "s",
+					roughfa.Otherwise: "f",
+				},
+				"f": {
+					'b': "f",
+				},
+			}).
+			Build()
+		if !assert.Nil(t, err) {
+			return
+		}
+		var (
+			d = m.Trim()
+			n = roughfa.FromDFA(m).Trim()
+		)
+		for _, w := range allWords("abx", 4) {
+			want := m.Accepts(w)
+			assert.Equal(t, want, d.Accepts(w), "dfa %q", w)
+			assert.Equal(t, want, n.Accepts(w), "nfa %q", w)
+		}
+		assert.True(t, d.Accepts("b"))
+		assert.True(t, n.Accepts("b"))
+	})
+}
@@ -0,0 +1,122 @@
+package roughfa
+
+import "strings"
+
+// RegexpOp is a type of the node of Regexp.
+type RegexpOp int
+
+const (
+	// EmptySetRegexpOp matches nothing.
+	EmptySetRegexpOp RegexpOp = iota
+	// EmptyRegexpOp matches the empty string.
+	EmptyRegexpOp
+	// CharRegexpOp matches Char.
+	CharRegexpOp
+	// ConcatRegexpOp matches the concatenation of Subs.
+	ConcatRegexpOp
+	// AlternateRegexpOp matches one of Subs.
+	AlternateRegexpOp
+	// StarRegexpOp matches zero or more Subs[0].
+	StarRegexpOp
+	// PlusRegexpOp matches one or more Subs[0].
+	PlusRegexpOp
+	// OptionalRegexpOp matches zero or one Subs[0].
+	OptionalRegexpOp
+)
+
+const (
+	// regexpMetaChars are the characters that have to be escaped to be literals.
+	regexpMetaChars = `\|*+?()`
+)
+
+type (
+	// Regexp is a node of the abstract syntax tree of the regular expression.
+	Regexp struct {
+		Op   RegexpOp
+		Char rune
+		Subs []*Regexp
+	}
+)
+
+// String returns the regular expression that ParseRegexp can read.
+// EmptySetRegexpOp is written as ∅, that is not a part of the syntax.
+func (s *Regexp) String() string {
+	var b strings.Builder
+	s.write(&b)
+	return b.String()
+}
+
+// precedence returns the binding strength of the node.
+// Larger binds tighter.
+func (s *Regexp) precedence() int {
+	switch s.Op {
+	case AlternateRegexpOp:
+		return 0
+	case ConcatRegexpOp:
+		return 1
+	case StarRegexpOp, PlusRegexpOp, OptionalRegexpOp:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func (s *Regexp) writeSub(b *strings.Builder, sub *Regexp, prec int) {
+	if sub.precedence() < prec {
+		b.WriteRune('(')
+		sub.write(b)
+		b.WriteRune(')')
+		return
+	}
+	sub.write(b)
+}
+
+func (s *Regexp) write(b *strings.Builder) {
+	switch s.Op {
+	case EmptySetRegexpOp:
+		b.WriteRune('∅')
+	case EmptyRegexpOp:
+		b.WriteString("()")
+	case CharRegexpOp:
+		writeRegexpChar(b, s.Char)
+	case ConcatRegexpOp:
+		for _, x := range s.Subs {
+			s.writeSub(b, x, 2)
+		}
+	case AlternateRegexpOp:
+		for i, x := range s.Subs {
+			if i > 0 {
+				b.WriteRune('|')
+			}
+			s.writeSub(b, x, 1)
+		}
+	case StarRegexpOp, PlusRegexpOp, OptionalRegexpOp:
+		s.writeSub(b, s.Subs[0], 3)
+		switch s.Op {
+		case StarRegexpOp:
+			b.WriteRune('*')
+		case PlusRegexpOp:
+			b.WriteRune('+')
+		default:
+			b.WriteRune('?')
+		}
+	default:
+		panic("unknown regexp op")
+	}
+}
+
+func writeRegexpChar(b *strings.Builder, c rune) {
+	switch c {
+	case '\n':
+		b.WriteString(`\n`)
+	case '\t':
+		b.WriteString(`\t`)
+	case '\r':
+		b.WriteString(`\r`)
+	default:
+		if strings.ContainsRune(regexpMetaChars, c) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+}
@@ -0,0 +1,120 @@
+package roughfa
+
+import (
+	"fmt"
+
+	"github.com/berquerant/roughfa/internal/set"
+)
+
+type (
+	// thompsonFragment is a part of the machine that has a single start state and a single accept state.
+	thompsonFragment struct {
+		start  string
+		accept string
+	}
+
+	thompsonConstructor struct {
+		states      set.StringSet
+		transitions map[string]map[rune]set.StringSet
+	}
+)
+
+// CompileRegexp parses a regular expression and creates a NFAMachine that accepts the same language.
+// See ParseRegexp for the syntax.
+func CompileRegexp(pattern string) (NFAMachine, error) {
+	re, err := ParseRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return NewNFAMachineFromRegexp(re), nil
+}
+
+// NewNFAMachineFromRegexp creates a NFAMachine from a regular expression by the thompson construction.
+// The states are named by sequential numbers and the machine has epsilon transitions.
+// The chars of the machine are not set, means universe.
+func NewNFAMachineFromRegexp(re *Regexp) NFAMachine {
+	c := &thompsonConstructor{
+		states:      set.NewStringSet(),
+		transitions: map[string]map[rune]set.StringSet{},
+	}
+	f := c.construct(re)
+	return &nfaMachine{
+		states:        c.states,
+		chars:         set.NewRuneSet(),
+		startStates:   set.NewStringSet(f.start),
+		acceptStates:  set.NewStringSet(f.accept),
+		transitions:   c.transitions,
+		currentStates: set.NewStringSet(f.start),
+	}
+}
+
+func (s *thompsonConstructor) newState() string {
+	x := fmt.Sprint(s.states.Len())
+	s.states.Add(x)
+	return x
+}
+
+func (s *thompsonConstructor) newFragment() *thompsonFragment {
+	return &thompsonFragment{
+		start:  s.newState(),
+		accept: s.newState(),
+	}
+}
+
+func (s *thompsonConstructor) connect(fromState string, c rune, toStates ...string) {
+	addTransitions(s.transitions, fromState, c, toStates...)
+}
+
+func (s *thompsonConstructor) construct(re *Regexp) *thompsonFragment {
+	switch re.Op {
+	case EmptySetRegexpOp:
+		return s.newFragment()
+	case EmptyRegexpOp:
+		f := s.newFragment()
+		s.connect(f.start, Epsilon, f.accept)
+		return f
+	case CharRegexpOp:
+		f := s.newFragment()
+		s.connect(f.start, re.Char, f.accept)
+		return f
+	case ConcatRegexpOp:
+		if len(re.Subs) == 0 {
+			return s.construct(&Regexp{Op: EmptyRegexpOp})
+		}
+		f := s.construct(re.Subs[0])
+		accept := f.accept
+		for _, x := range re.Subs[1:] {
+			g := s.construct(x)
+			s.connect(accept, Epsilon, g.start)
+			accept = g.accept
+		}
+		return &thompsonFragment{
+			start:  f.start,
+			accept: accept,
+		}
+	case AlternateRegexpOp:
+		f := s.newFragment()
+		for _, x := range re.Subs {
+			g := s.construct(x)
+			s.connect(f.start, Epsilon, g.start)
+			s.connect(g.accept, Epsilon, f.accept)
+		}
+		return f
+	case StarRegexpOp, PlusRegexpOp, OptionalRegexpOp:
+		f := s.newFragment()
+		g := s.construct(re.Subs[0])
+		s.connect(f.start, Epsilon, g.start)
+		s.connect(g.accept, Epsilon, f.accept)
+		if re.Op != PlusRegexpOp {
+			// zero times
+			s.connect(f.start, Epsilon, f.accept)
+		}
+		if re.Op != OptionalRegexpOp {
+			// more times
+			s.connect(g.accept, Epsilon, g.start)
+		}
+		return f
+	default:
+		panic("unknown regexp op")
+	}
+}
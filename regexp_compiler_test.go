@@ -0,0 +1,102 @@
+package roughfa_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+// nfaAccepts returns true if m accepts the input.
+func nfaAccepts(m roughfa.NFAMachine, input string) bool {
+	m.Reset()
+	for _, c := range input {
+		if err := m.Put(c); err != nil {
+			return false
+		}
+	}
+	return m.IsAccepted()
+}
+
+// allWords returns all words over the chars whose length is not greater than maxLen.
+func allWords(chars string, maxLen int) []string {
+	var (
+		words = []string{""}
+		last  = []string{""}
+	)
+	for i := 0; i < maxLen; i++ {
+		var next []string
+		for _, w := range last {
+			for _, c := range chars {
+				next = append(next, w+string(c))
+			}
+		}
+		words = append(words, next...)
+		last = next
+	}
+	return words
+}
+
+type compileRegexpTestcase struct {
+	name    string
+	pattern string
+	chars   string
+}
+
+func (s compileRegexpTestcase) test(t *testing.T) {
+	m, err := roughfa.CompileRegexp(s.pattern)
+	if !assert.Nil(t, err) {
+		return
+	}
+	want := regexp.MustCompile(`^(?:` + s.pattern + `)$`)
+	for _, w := range allWords(s.chars, 5) {
+		assert.Equal(t, want.MatchString(w), nfaAccepts(m, w), "%q", w)
+	}
+}
+
+func TestCompileRegexp(t *testing.T) {
+	for _, tc := range []*compileRegexpTestcase{
+		{
+			name:  "empty",
+			chars: "a",
+		},
+		{
+			name:    "(a|b)*a",
+			pattern: "(a|b)*a",
+			chars:   "ab",
+		},
+		{
+			name:    "a(b|c)*d",
+			pattern: "a(b|c)*d",
+			chars:   "abcd",
+		},
+		{
+			name:    "a+b?",
+			pattern: "a+b?",
+			chars:   "ab",
+		},
+		{
+			name:    "(a*)*b",
+			pattern: "(a*)*b",
+			chars:   "ab",
+		},
+		{
+			name:    "(a|)(b|c?)+",
+			pattern: "(a|)(b|c?)+",
+			chars:   "abc",
+		},
+		{
+			name:    `\*\|a`,
+			pattern: `\*\|a`,
+			chars:   "*|a",
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestCompileRegexpError(t *testing.T) {
+	_, err := roughfa.CompileRegexp("(a")
+	assert.ErrorIs(t, err, roughfa.ErrMissingParen)
+}
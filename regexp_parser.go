@@ -0,0 +1,183 @@
+package roughfa
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+type (
+	regexpParser struct {
+		pattern string
+		pos     int
+	}
+)
+
+// ParseRegexp parses a regular expression.
+//
+// The syntax is:
+//
+//	x|y  alternation
+//	xy   concatenation
+//	x*   zero or more x
+//	x+   one or more x
+//	x?   zero or one x
+//	(x)  grouping, () matches the empty string
+//	\c   the literal c for the punctuation c, \n, \t and \r
+//
+// Returns a RegexpError if the pattern is invalid.
+func ParseRegexp(pattern string) (*Regexp, error) {
+	p := &regexpParser{
+		pattern: pattern,
+	}
+	re, err := p.parseAlternate()
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		// only an unmatched ) stops parseAlternate
+		return nil, p.errorAt(p.pos, ErrUnexpectedParen)
+	}
+	return re, nil
+}
+
+func (s *regexpParser) eof() bool { return s.pos >= len(s.pattern) }
+
+func (s *regexpParser) peek() (rune, int) { return utf8.DecodeRuneInString(s.pattern[s.pos:]) }
+
+func (s *regexpParser) errorAt(pos int, err error) error {
+	return &RegexpError{
+		Pattern: s.pattern,
+		Pos:     pos,
+		Err:     err,
+	}
+}
+
+func (s *regexpParser) parseAlternate() (*Regexp, error) {
+	var subs []*Regexp
+	for {
+		x, err := s.parseConcat()
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, x)
+		if s.eof() {
+			break
+		}
+		if c, _ := s.peek(); c != '|' {
+			break
+		}
+		s.pos++
+	}
+	if len(subs) == 1 {
+		return subs[0], nil
+	}
+	return &Regexp{
+		Op:   AlternateRegexpOp,
+		Subs: subs,
+	}, nil
+}
+
+func (s *regexpParser) parseConcat() (*Regexp, error) {
+	var subs []*Regexp
+	for !s.eof() {
+		if c, _ := s.peek(); c == '|' || c == ')' {
+			break
+		}
+		x, err := s.parseRepeat()
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, x)
+	}
+	switch len(subs) {
+	case 0:
+		return &Regexp{Op: EmptyRegexpOp}, nil
+	case 1:
+		return subs[0], nil
+	default:
+		return &Regexp{
+			Op:   ConcatRegexpOp,
+			Subs: subs,
+		}, nil
+	}
+}
+
+func (s *regexpParser) parseRepeat() (*Regexp, error) {
+	x, err := s.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	for !s.eof() {
+		var op RegexpOp
+		switch c, _ := s.peek(); c {
+		case '*':
+			op = StarRegexpOp
+		case '+':
+			op = PlusRegexpOp
+		case '?':
+			op = OptionalRegexpOp
+		default:
+			return x, nil
+		}
+		s.pos++
+		x = &Regexp{
+			Op:   op,
+			Subs: []*Regexp{x},
+		}
+	}
+	return x, nil
+}
+
+func (s *regexpParser) parseAtom() (*Regexp, error) {
+	start := s.pos
+	c, size := s.peek()
+	switch c {
+	case '*', '+', '?':
+		return nil, s.errorAt(start, ErrMissingRepeatArgument)
+	case '(':
+		s.pos += size
+		x, err := s.parseAlternate()
+		if err != nil {
+			return nil, err
+		}
+		if s.eof() {
+			return nil, s.errorAt(start, ErrMissingParen)
+		}
+		s.pos++ // )
+		return x, nil
+	case '\\':
+		s.pos += size
+		if s.eof() {
+			return nil, s.errorAt(start, ErrTrailingBackslash)
+		}
+		e, esize := s.peek()
+		s.pos += esize
+		switch {
+		case e == 'n':
+			e = '\n'
+		case e == 't':
+			e = '\t'
+		case e == 'r':
+			e = '\r'
+		case e == Epsilon:
+			return nil, s.errorAt(start, ErrInvalidRegexpChar)
+		case e < utf8.RuneSelf && (unicode.IsLetter(e) || unicode.IsDigit(e)):
+			return nil, s.errorAt(start, ErrInvalidEscape)
+		}
+		return &Regexp{
+			Op:   CharRegexpOp,
+			Char: e,
+		}, nil
+	case Epsilon:
+		return nil, s.errorAt(start, ErrInvalidRegexpChar)
+	case utf8.RuneError:
+		if size <= 1 {
+			return nil, s.errorAt(start, ErrInvalidRegexpChar)
+		}
+	}
+	s.pos += size
+	return &Regexp{
+		Op:   CharRegexpOp,
+		Char: c,
+	}, nil
+}
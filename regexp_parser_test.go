@@ -0,0 +1,125 @@
+package roughfa_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+type parseRegexpTestcase struct {
+	name    string
+	pattern string
+	want    string
+	err     error
+	pos     int
+}
+
+func (s parseRegexpTestcase) test(t *testing.T) {
+	re, err := roughfa.ParseRegexp(s.pattern)
+	if s.err != nil {
+		assert.True(t, errors.Is(err, s.err), "%v", err)
+		var rerr *roughfa.RegexpError
+		if assert.True(t, errors.As(err, &rerr)) {
+			assert.Equal(t, s.pos, rerr.Pos)
+		}
+		return
+	}
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, s.want, re.String())
+}
+
+func TestParseRegexp(t *testing.T) {
+	for _, tc := range []*parseRegexpTestcase{
+		{
+			name: "empty",
+			want: "()",
+		},
+		{
+			name:    "char",
+			pattern: "a",
+			want:    "a",
+		},
+		{
+			name:    "concat",
+			pattern: "abc",
+			want:    "abc",
+		},
+		{
+			name:    "alternate",
+			pattern: "a|bc|",
+			want:    "a|bc|()",
+		},
+		{
+			name:    "repeat",
+			pattern: "a*b+c?",
+			want:    "a*b+c?",
+		},
+		{
+			name:    "nested repeat",
+			pattern: "a*?",
+			want:    "(a*)?",
+		},
+		{
+			name:    "group",
+			pattern: "(a|b)*a",
+			want:    "(a|b)*a",
+		},
+		{
+			name:    "redundant group",
+			pattern: "((a))(b)",
+			want:    "ab",
+		},
+		{
+			name:    "escape",
+			pattern: `\(\*\\\n`,
+			want:    `\(\*\\\n`,
+		},
+		{
+			name:    "non ascii",
+			pattern: "é|日本",
+			want:    "é|日本",
+		},
+		{
+			name:    "missing paren",
+			pattern: "a(b|c",
+			err:     roughfa.ErrMissingParen,
+			pos:     1,
+		},
+		{
+			name:    "unexpected paren",
+			pattern: "ab)c",
+			err:     roughfa.ErrUnexpectedParen,
+			pos:     2,
+		},
+		{
+			name:    "missing repeat argument",
+			pattern: "a|*",
+			err:     roughfa.ErrMissingRepeatArgument,
+			pos:     2,
+		},
+		{
+			name:    "trailing backslash",
+			pattern: `ab\`,
+			err:     roughfa.ErrTrailingBackslash,
+			pos:     2,
+		},
+		{
+			name:    "invalid escape",
+			pattern: `a\d`,
+			err:     roughfa.ErrInvalidEscape,
+			pos:     1,
+		},
+		{
+			name:    "epsilon",
+			pattern: "aε",
+			err:     roughfa.ErrInvalidRegexpChar,
+			pos:     1,
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
@@ -0,0 +1,282 @@
+package roughfa
+
+import "sort"
+
+// SimplifyRegexp returns a regular expression that matches the same language as re and is not larger than re.
+// It removes redundant empty strings and empty sets, merges duplicated alternatives,
+// collapses nested repetitions and factors out common prefixes and suffixes of alternatives.
+func SimplifyRegexp(re *Regexp) *Regexp {
+	switch re.Op {
+	case ConcatRegexpOp, AlternateRegexpOp:
+		subs := make([]*Regexp, len(re.Subs))
+		for i, x := range re.Subs {
+			subs[i] = SimplifyRegexp(x)
+		}
+		if re.Op == ConcatRegexpOp {
+			return newConcatRegexp(subs...)
+		}
+		return newAlternateRegexp(subs...)
+	case StarRegexpOp:
+		return newStarRegexp(SimplifyRegexp(re.Subs[0]))
+	case PlusRegexpOp:
+		return newPlusRegexp(SimplifyRegexp(re.Subs[0]))
+	case OptionalRegexpOp:
+		return newOptionalRegexp(SimplifyRegexp(re.Subs[0]))
+	default:
+		return re
+	}
+}
+
+func newEmptySetRegexp() *Regexp    { return &Regexp{Op: EmptySetRegexpOp} }
+func newEmptyRegexp() *Regexp       { return &Regexp{Op: EmptyRegexpOp} }
+func newCharRegexp(c rune) *Regexp  { return &Regexp{Op: CharRegexpOp, Char: c} }
+func equalRegexp(x, y *Regexp) bool { return x.String() == y.String() }
+func isNullableRegexpOp(op RegexpOp) bool {
+	return op == EmptyRegexpOp || op == StarRegexpOp || op == OptionalRegexpOp
+}
+
+// regexpSize returns the number of the nodes.
+func regexpSize(re *Regexp) int {
+	n := 1
+	for _, x := range re.Subs {
+		n += regexpSize(x)
+	}
+	return n
+}
+
+func newConcatRegexp(xs ...*Regexp) *Regexp {
+	var subs []*Regexp
+	for _, x := range xs {
+		switch x.Op {
+		case EmptySetRegexpOp:
+			return newEmptySetRegexp()
+		case EmptyRegexpOp:
+			continue
+		case ConcatRegexpOp:
+			subs = append(subs, x.Subs...)
+		default:
+			subs = append(subs, x)
+		}
+	}
+	// xx* and x*x to x+, x*x* to x*
+	var merged []*Regexp
+	for _, x := range subs {
+		if len(merged) == 0 {
+			merged = append(merged, x)
+			continue
+		}
+		last := merged[len(merged)-1]
+		switch {
+		case last.Op == StarRegexpOp && x.Op == StarRegexpOp && equalRegexp(last, x):
+			continue
+		case last.Op == StarRegexpOp && equalRegexp(last.Subs[0], x):
+			merged[len(merged)-1] = newPlusRegexp(x)
+			continue
+		case x.Op == StarRegexpOp && equalRegexp(last, x.Subs[0]):
+			merged[len(merged)-1] = newPlusRegexp(last)
+			continue
+		}
+		merged = append(merged, x)
+	}
+	switch len(merged) {
+	case 0:
+		return newEmptyRegexp()
+	case 1:
+		return merged[0]
+	default:
+		return &Regexp{
+			Op:   ConcatRegexpOp,
+			Subs: merged,
+		}
+	}
+}
+
+func newAlternateRegexp(xs ...*Regexp) *Regexp {
+	var (
+		subs     []*Regexp
+		seen     = map[string]bool{}
+		hasEmpty bool
+	)
+	var add func(x *Regexp)
+	add = func(x *Regexp) {
+		switch x.Op {
+		case EmptySetRegexpOp:
+			return
+		case EmptyRegexpOp:
+			hasEmpty = true
+			return
+		case OptionalRegexpOp:
+			hasEmpty = true
+			add(x.Subs[0])
+			return
+		case AlternateRegexpOp:
+			for _, y := range x.Subs {
+				add(y)
+			}
+			return
+		}
+		k := x.String()
+		if seen[k] {
+			return
+		}
+		seen[k] = true
+		subs = append(subs, x)
+	}
+	for _, x := range xs {
+		add(x)
+	}
+	sort.SliceStable(subs, func(i, j int) bool { return subs[i].String() < subs[j].String() })
+	subs = factorRegexpSuffix(factorRegexpPrefix(subs))
+
+	var alt *Regexp
+	switch len(subs) {
+	case 0:
+		if hasEmpty {
+			return newEmptyRegexp()
+		}
+		return newEmptySetRegexp()
+	case 1:
+		alt = subs[0]
+	default:
+		alt = &Regexp{
+			Op:   AlternateRegexpOp,
+			Subs: subs,
+		}
+	}
+	if hasEmpty {
+		return newOptionalRegexp(alt)
+	}
+	return alt
+}
+
+// factorRegexpPrefix groups the alternatives by the first factor, ab|ac to a(b|c).
+// Requires that subs are sorted.
+func factorRegexpPrefix(subs []*Regexp) []*Regexp {
+	head := func(x *Regexp) (*Regexp, *Regexp) {
+		if x.Op != ConcatRegexpOp {
+			return x, newEmptyRegexp()
+		}
+		return x.Subs[0], newConcatRegexp(x.Subs[1:]...)
+	}
+	var result []*Regexp
+	for i := 0; i < len(subs); {
+		h, _ := head(subs[i])
+		j := i + 1
+		for j < len(subs) {
+			g, _ := head(subs[j])
+			if !equalRegexp(h, g) {
+				break
+			}
+			j++
+		}
+		if j-i == 1 {
+			result = append(result, subs[i])
+			i = j
+			continue
+		}
+		tails := make([]*Regexp, 0, j-i)
+		for _, x := range subs[i:j] {
+			_, tail := head(x)
+			tails = append(tails, tail)
+		}
+		result = append(result, newConcatRegexp(h, newAlternateRegexp(tails...)))
+		i = j
+	}
+	return result
+}
+
+// factorRegexpSuffix groups the alternatives by the last factor, ac|bc to (a|b)c.
+func factorRegexpSuffix(subs []*Regexp) []*Regexp {
+	last := func(x *Regexp) (*Regexp, *Regexp) {
+		if x.Op != ConcatRegexpOp {
+			return newEmptyRegexp(), x
+		}
+		n := len(x.Subs) - 1
+		return newConcatRegexp(x.Subs[:n]...), x.Subs[n]
+	}
+	var (
+		keys   []string
+		groups = map[string][]*Regexp{}
+	)
+	for _, x := range subs {
+		_, l := last(x)
+		k := l.String()
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], x)
+	}
+	if len(keys) == len(subs) {
+		return subs
+	}
+	result := make([]*Regexp, 0, len(keys))
+	for _, k := range keys {
+		g := groups[k]
+		if len(g) == 1 {
+			result = append(result, g[0])
+			continue
+		}
+		var (
+			inits = make([]*Regexp, len(g))
+			l     *Regexp
+		)
+		for i, x := range g {
+			inits[i], l = last(x)
+		}
+		result = append(result, newConcatRegexp(newAlternateRegexp(inits...), l))
+	}
+	return result
+}
+
+func newStarRegexp(x *Regexp) *Regexp {
+	switch x.Op {
+	case EmptySetRegexpOp, EmptyRegexpOp:
+		return newEmptyRegexp()
+	case StarRegexpOp:
+		return x
+	case PlusRegexpOp, OptionalRegexpOp:
+		return newStarRegexp(x.Subs[0])
+	}
+	return &Regexp{
+		Op:   StarRegexpOp,
+		Subs: []*Regexp{x},
+	}
+}
+
+func newPlusRegexp(x *Regexp) *Regexp {
+	switch x.Op {
+	case EmptySetRegexpOp:
+		return newEmptySetRegexp()
+	case EmptyRegexpOp, StarRegexpOp, PlusRegexpOp:
+		return x
+	case OptionalRegexpOp:
+		return newStarRegexp(x.Subs[0])
+	}
+	return &Regexp{
+		Op:   PlusRegexpOp,
+		Subs: []*Regexp{x},
+	}
+}
+
+func newOptionalRegexp(x *Regexp) *Regexp {
+	switch x.Op {
+	case EmptySetRegexpOp, EmptyRegexpOp:
+		return newEmptyRegexp()
+	case StarRegexpOp, OptionalRegexpOp:
+		return x
+	case PlusRegexpOp:
+		return newStarRegexp(x.Subs[0])
+	}
+	if x.Op == AlternateRegexpOp {
+		// (x|y*)? to x|y*
+		for _, y := range x.Subs {
+			if isNullableRegexpOp(y.Op) {
+				return x
+			}
+		}
+	}
+	return &Regexp{
+		Op:   OptionalRegexpOp,
+		Subs: []*Regexp{x},
+	}
+}
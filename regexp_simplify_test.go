@@ -0,0 +1,84 @@
+package roughfa_test
+
+import (
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+type simplifyRegexpTestcase struct {
+	name    string
+	pattern string
+	want    string
+}
+
+func (s simplifyRegexpTestcase) test(t *testing.T) {
+	re, err := roughfa.ParseRegexp(s.pattern)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, s.want, roughfa.SimplifyRegexp(re).String())
+}
+
+func TestSimplifyRegexp(t *testing.T) {
+	for _, tc := range []*simplifyRegexpTestcase{
+		{
+			name:    "empty in concat",
+			pattern: "a()b",
+			want:    "ab",
+		},
+		{
+			name:    "duplicated alternatives",
+			pattern: "b|a|b",
+			want:    "a|b",
+		},
+		{
+			name:    "empty alternative",
+			pattern: "a|",
+			want:    "a?",
+		},
+		{
+			name:    "nullable alternative",
+			pattern: "a*|b|",
+			want:    "a*|b",
+		},
+		{
+			name:    "nested repeat",
+			pattern: "((a*)+)?",
+			want:    "a*",
+		},
+		{
+			name:    "optional plus",
+			pattern: "(a+)?",
+			want:    "a*",
+		},
+		{
+			name:    "concat star",
+			pattern: "aa*",
+			want:    "a+",
+		},
+		{
+			name:    "star concat",
+			pattern: "a*a*",
+			want:    "a*",
+		},
+		{
+			name:    "common prefix",
+			pattern: "abc|abd|e",
+			want:    "ab(c|d)|e",
+		},
+		{
+			name:    "common suffix",
+			pattern: "(a|b)+c|c",
+			want:    "(a|b)*c",
+		},
+		{
+			name:    "prefix only",
+			pattern: "ab|a",
+			want:    "ab?",
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
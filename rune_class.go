@@ -0,0 +1,182 @@
+package roughfa
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type (
+	// RuneRange is the runes from Lo to Hi, inclusive.
+	RuneRange struct {
+		Lo rune
+		Hi rune
+	}
+
+	// RuneClass is a set of runes represented by ranges.
+	// The zero value is the empty set.
+	RuneClass struct {
+		// ranges are sorted, disjoint and not adjacent
+		ranges []RuneRange
+	}
+)
+
+// NewRuneClass creates a RuneClass that consists of the ranges.
+// The ranges whose Lo is greater than Hi are ignored.
+func NewRuneClass(ranges ...RuneRange) RuneClass {
+	xs := make([]RuneRange, 0, len(ranges))
+	for _, x := range ranges {
+		if x.Lo <= x.Hi {
+			xs = append(xs, x)
+		}
+	}
+	sort.Slice(xs, func(i, j int) bool { return xs[i].Lo < xs[j].Lo })
+	var normalized []RuneRange
+	for _, x := range xs {
+		if n := len(normalized); n > 0 && x.Lo <= normalized[n-1].Hi+1 {
+			if x.Hi > normalized[n-1].Hi {
+				normalized[n-1].Hi = x.Hi
+			}
+			continue
+		}
+		normalized = append(normalized, x)
+	}
+	return RuneClass{ranges: normalized}
+}
+
+// NewRuneClassOf creates a RuneClass that consists of the runes.
+func NewRuneClassOf(runes ...rune) RuneClass {
+	xs := make([]RuneRange, len(runes))
+	for i, r := range runes {
+		xs[i] = RuneRange{Lo: r, Hi: r}
+	}
+	return NewRuneClass(xs...)
+}
+
+// NewRuneClassFromTable creates a RuneClass that consists of the runes in the table,
+// e.g. unicode.Letter.
+func NewRuneClassFromTable(table *unicode.RangeTable) RuneClass {
+	var xs []RuneRange
+	for _, x := range table.R16 {
+		for r := rune(x.Lo); r <= rune(x.Hi); r += rune(x.Stride) {
+			if x.Stride == 1 {
+				xs = append(xs, RuneRange{Lo: rune(x.Lo), Hi: rune(x.Hi)})
+				break
+			}
+			xs = append(xs, RuneRange{Lo: r, Hi: r})
+		}
+	}
+	for _, x := range table.R32 {
+		for r := rune(x.Lo); r <= rune(x.Hi); r += rune(x.Stride) {
+			if x.Stride == 1 {
+				xs = append(xs, RuneRange{Lo: rune(x.Lo), Hi: rune(x.Hi)})
+				break
+			}
+			xs = append(xs, RuneRange{Lo: r, Hi: r})
+		}
+	}
+	return NewRuneClass(xs...)
+}
+
+// AnyRuneClass returns the RuneClass that consists of all runes.
+func AnyRuneClass() RuneClass { return NewRuneClass(RuneRange{Lo: 0, Hi: unicode.MaxRune}) }
+
+// Ranges returns the sorted and disjoint ranges.
+func (s RuneClass) Ranges() []RuneRange { return append([]RuneRange{}, s.ranges...) }
+
+// IsEmpty returns true if this has no runes.
+func (s RuneClass) IsEmpty() bool { return len(s.ranges) == 0 }
+
+// Contains returns true if this has the rune.
+func (s RuneClass) Contains(r rune) bool {
+	i := sort.Search(len(s.ranges), func(i int) bool { return s.ranges[i].Hi >= r })
+	return i < len(s.ranges) && s.ranges[i].Lo <= r
+}
+
+// Equal returns true if this and other have the same runes.
+func (s RuneClass) Equal(other RuneClass) bool {
+	if len(s.ranges) != len(other.ranges) {
+		return false
+	}
+	for i, x := range s.ranges {
+		if x != other.ranges[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Union returns the runes in this or other.
+func (s RuneClass) Union(other RuneClass) RuneClass {
+	return NewRuneClass(append(s.Ranges(), other.ranges...)...)
+}
+
+// Negate returns the runes not in this.
+func (s RuneClass) Negate() RuneClass {
+	var (
+		xs []RuneRange
+		lo rune
+	)
+	for _, x := range s.ranges {
+		if x.Lo > lo {
+			xs = append(xs, RuneRange{Lo: lo, Hi: x.Lo - 1})
+		}
+		lo = x.Hi + 1
+	}
+	if lo <= unicode.MaxRune {
+		xs = append(xs, RuneRange{Lo: lo, Hi: unicode.MaxRune})
+	}
+	return RuneClass{ranges: xs}
+}
+
+// Intersect returns the runes in both this and other.
+func (s RuneClass) Intersect(other RuneClass) RuneClass {
+	return s.Negate().Union(other.Negate()).Negate()
+}
+
+// Minus returns the runes in this but not in other.
+func (s RuneClass) Minus(other RuneClass) RuneClass { return s.Intersect(other.Negate()) }
+
+// String returns the class like [a-z], [^a-z] if the negation is simpler, or . if all runes.
+func (s RuneClass) String() string {
+	switch {
+	case len(s.ranges) == 0:
+		return "[]"
+	case len(s.ranges) == 1 && s.ranges[0].Lo == s.ranges[0].Hi:
+		return escapeRuneClassChar(s.ranges[0].Lo)
+	}
+	if n := s.Negate(); len(n.ranges) < len(s.ranges) || s.ranges[len(s.ranges)-1].Hi == unicode.MaxRune {
+		if n.IsEmpty() {
+			return "."
+		}
+		return "[^" + n.rangesString() + "]"
+	}
+	return "[" + s.rangesString() + "]"
+}
+
+func (s RuneClass) rangesString() string {
+	var b strings.Builder
+	for _, x := range s.ranges {
+		b.WriteString(escapeRuneClassChar(x.Lo))
+		switch {
+		case x.Lo == x.Hi:
+		case x.Lo+1 == x.Hi:
+			b.WriteString(escapeRuneClassChar(x.Hi))
+		default:
+			b.WriteString("-" + escapeRuneClassChar(x.Hi))
+		}
+	}
+	return b.String()
+}
+
+func escapeRuneClassChar(r rune) string {
+	switch {
+	case strings.ContainsRune(`\[]^-.`, r):
+		return `\` + string(r)
+	case unicode.IsPrint(r):
+		return string(r)
+	default:
+		return fmt.Sprintf(`\x{%x}`, r)
+	}
+}
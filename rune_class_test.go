@@ -0,0 +1,51 @@
+package roughfa_test
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuneClass(t *testing.T) {
+	var (
+		lower  = roughfa.NewRuneClass(roughfa.RuneRange{Lo: 'a', Hi: 'z'})
+		vowels = roughfa.NewRuneClassOf('u', 'a', 'e', 'i', 'o', 'a')
+		digits = roughfa.NewRuneClassFromTable(unicode.Digit)
+	)
+	for _, tc := range []struct {
+		name  string
+		class roughfa.RuneClass
+		want  string
+	}{
+		{name: "empty", class: roughfa.RuneClass{}, want: "[]"},
+		{name: "any", class: roughfa.AnyRuneClass(), want: "."},
+		{name: "single", class: roughfa.NewRuneClassOf('-'), want: `\-`},
+		{name: "range", class: lower, want: "[a-z]"},
+		{name: "runes", class: vowels, want: "[aeiou]"},
+		{name: "merge", class: roughfa.NewRuneClass(
+			roughfa.RuneRange{Lo: 'a', Hi: 'f'},
+			roughfa.RuneRange{Lo: 'g', Hi: 'k'},
+			roughfa.RuneRange{Lo: 'A', Hi: 'B'},
+			roughfa.RuneRange{Lo: 'z', Hi: 'a'},
+		), want: "[ABa-k]"},
+		{name: "negate", class: lower.Negate(), want: "[^a-z]"},
+		{name: "intersect", class: lower.Intersect(roughfa.NewRuneClassOf('A', 'b', 'c', '0')), want: "[bc]"},
+		{name: "minus", class: lower.Minus(vowels).Intersect(roughfa.NewRuneClass(roughfa.RuneRange{Lo: 'a', Hi: 'h'})), want: "[b-df-h]"},
+		{name: "union", class: lower.Union(roughfa.NewRuneClassOf('0', '1')), want: "[01a-z]"},
+		{name: "control", class: roughfa.NewRuneClassOf('\n', '^'), want: `[\x{a}\^]`},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.class.String())
+		})
+	}
+
+	assert.True(t, digits.Contains('٣'))
+	assert.False(t, digits.Contains('a'))
+	assert.True(t, roughfa.NewRuneClassFromTable(unicode.Hiragana).Contains('あ'))
+	assert.True(t, lower.Negate().Negate().Equal(lower))
+	assert.True(t, lower.Intersect(lower.Negate()).IsEmpty())
+	assert.True(t, lower.Union(lower.Negate()).Equal(roughfa.AnyRuneClass()))
+}
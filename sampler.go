@@ -0,0 +1,135 @@
+package roughfa
+
+import (
+	"math/big"
+	"math/rand"
+)
+
+type (
+	// Sampler generates random words accepted by a machine.
+	Sampler interface {
+		// Uniform returns a word chosen uniformly from the accepted words of length n.
+		// Returns false if no such words.
+		Uniform(n int) (string, bool)
+		// RandomWalk returns an accepted word by walking from the start state.
+		// The walk chooses a character uniformly among the characters that can lead to an accept state,
+		// and stops at an accept state with the probability stop.
+		// The words are not uniform, short words and words through the states with few transitions are likely.
+		// Returns false if the walk reaches maxLen characters at a state that is not an accept state.
+		RandomWalk(maxLen int, stop float64) (string, bool)
+	}
+
+	sampler struct {
+		// m is a dfa that consists of the useful states.
+		m        *nfaMachine
+		alphabet []rune
+		rnd      *rand.Rand
+		// counts[k][q] is the number of the words of length k that lead q to an accept state
+		counts []map[string]*big.Int
+	}
+)
+
+// NewSampler creates a new Sampler.
+// The machine is determinized unless it is a dfa, and src is the source of the randomness.
+func NewSampler(m NFAMachine, src rand.Source) Sampler {
+	var (
+		d      = asNFAMachine(m).determinize()
+		useful = d.reachableStates().And(d.coReachableStates())
+		u      = d.restrict(useful)
+	)
+	return &sampler{
+		m:        u,
+		alphabet: sortedRunes(u.alphabet()),
+		rnd:      rand.New(src),
+	}
+}
+
+func (s *sampler) Uniform(n int) (string, bool) {
+	if n < 0 || s.m.startStates.Len() == 0 {
+		return "", false
+	}
+	state := s.m.startStates.Unwrap()[0]
+	if s.count(state, n).Sign() == 0 {
+		return "", false
+	}
+	word := make([]rune, 0, n)
+	for k := n; k > 0; k-- {
+		// choose a character in proportion to the number of the words after it
+		x := new(big.Int).Rand(s.rnd, s.count(state, k))
+		for _, c := range s.alphabet {
+			next, ok := s.m.dfaNext(state, c)
+			if !ok {
+				continue
+			}
+			y := s.count(next, k-1)
+			if x.Cmp(y) < 0 {
+				word = append(word, c)
+				state = next
+				break
+			}
+			x.Sub(x, y)
+		}
+	}
+	return string(word), true
+}
+
+func (s *sampler) RandomWalk(maxLen int, stop float64) (string, bool) {
+	if s.m.startStates.Len() == 0 {
+		return "", false
+	}
+	var (
+		state = s.m.startStates.Unwrap()[0]
+		word  []rune
+	)
+	for len(word) <= maxLen {
+		if s.m.acceptStates.In(state) && s.rnd.Float64() < stop {
+			return string(word), true
+		}
+		var chars []rune
+		for _, c := range s.alphabet {
+			if _, ok := s.m.dfaNext(state, c); ok {
+				chars = append(chars, c)
+			}
+		}
+		if len(chars) == 0 || len(word) == maxLen {
+			break
+		}
+		c := chars[s.rnd.Intn(len(chars))]
+		state, _ = s.m.dfaNext(state, c)
+		word = append(word, c)
+	}
+	if s.m.acceptStates.In(state) {
+		return string(word), true
+	}
+	return "", false
+}
+
+// count returns the number of the words of length k that lead the state to an accept state.
+func (s *sampler) count(state string, k int) *big.Int {
+	for len(s.counts) <= k {
+		layer := map[string]*big.Int{}
+		if len(s.counts) == 0 {
+			for _, x := range s.m.acceptStates.Unwrap() {
+				layer[x] = big.NewInt(1)
+			}
+		} else {
+			prev := s.counts[len(s.counts)-1]
+			for from, x := range s.m.transitions {
+				n := big.NewInt(0)
+				for _, toStates := range x {
+					if y, ok := prev[toStates.Unwrap()[0]]; ok {
+						n.Add(n, y)
+					}
+				}
+				if n.Sign() > 0 {
+					layer[from] = n
+				}
+			}
+		}
+		s.counts = append(s.counts, layer)
+	}
+	if x, ok := s.counts[k][state]; ok {
+		return x
+	}
+	return big.NewInt(0)
+}
@@ -0,0 +1,80 @@
+package roughfa_test
+
+import (
+	"math/rand"
+	"regexp"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamplerUniform(t *testing.T) {
+	const (
+		pattern = "a(a|b)(a|b)|b(aa|bb)|bab*"
+		n       = 3
+		trials  = 6000
+	)
+	m, err := roughfa.CompileRegexp(pattern)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var (
+		s      = roughfa.NewSampler(m, rand.NewSource(1))
+		r      = regexp.MustCompile(`^(?:` + pattern + `)$`)
+		counts = map[string]int{}
+	)
+	for i := 0; i < trials; i++ {
+		w, ok := s.Uniform(n)
+		if !assert.True(t, ok) {
+			return
+		}
+		assert.True(t, r.MatchString(w), "%q", w)
+		assert.Equal(t, n, len(w), "%q", w)
+		counts[w]++
+	}
+	// aaa, aab, aba, abb, baa, bbb, bab
+	assert.Equal(t, 7, len(counts))
+	for w, c := range counts {
+		assert.InDelta(t, trials/7, c, trials/7/5, "%q", w)
+	}
+
+	_, ok := s.Uniform(4)
+	assert.True(t, ok)
+	_, ok = s.Uniform(-1)
+	assert.False(t, ok)
+	empty, err := roughfa.CompileRegexp("ab")
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, ok = roughfa.NewSampler(empty, rand.NewSource(1)).Uniform(3)
+	assert.False(t, ok)
+}
+
+func TestSamplerRandomWalk(t *testing.T) {
+	const pattern = "(ab|c)*d"
+	m, err := roughfa.CompileRegexp(pattern)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var (
+		s = roughfa.NewSampler(m, rand.NewSource(1))
+		r = regexp.MustCompile(`^(?:` + pattern + `)$`)
+	)
+	for i := 0; i < 100; i++ {
+		w, ok := s.RandomWalk(20, 0.5)
+		if !ok {
+			continue
+		}
+		assert.True(t, r.MatchString(w), "%q", w)
+		assert.LessOrEqual(t, len(w), 20, "%q", w)
+	}
+	_, ok := s.RandomWalk(0, 1)
+	assert.False(t, ok)
+	for i := 0; i < 10; i++ {
+		// the only accepted word of length 1 is d
+		if w, ok := s.RandomWalk(1, 1); ok {
+			assert.Equal(t, "d", w)
+		}
+	}
+}
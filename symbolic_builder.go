@@ -0,0 +1,83 @@
+package roughfa
+
+import "github.com/berquerant/roughfa/internal/set"
+
+type (
+	// SymbolicNFAMachineBuilder is a builder of SymbolicNFAMachine.
+	SymbolicNFAMachineBuilder interface {
+		// States configures the states of the machine.
+		States(states []string) SymbolicNFAMachineBuilder
+		// StartStates configures the initial states of the machine.
+		// Required.
+		// Must be included in States.
+		StartStates(state []string) SymbolicNFAMachineBuilder
+		// AcceptStates configures the accept states of the machine.
+		// Required.
+		// Must be a subset of States.
+		AcceptStates(acceptStates []string) SymbolicNFAMachineBuilder
+		// Transitions configures the edges from each state.
+		// Required.
+		Transitions(transitions map[string][]SymbolicEdge) SymbolicNFAMachineBuilder
+		// Build creates a new SymbolicNFAMachine.
+		// Returns an error if some validations fails.
+		Build() (SymbolicNFAMachine, error)
+	}
+
+	symbolicNFAMachineBuilder struct {
+		states       []string
+		startStates  []string
+		acceptStates []string
+		transitions  map[string][]SymbolicEdge
+	}
+)
+
+// NewSymbolicNFAMachineBuilder creates a new SymbolicNFAMachineBuilder.
+func NewSymbolicNFAMachineBuilder() SymbolicNFAMachineBuilder { return &symbolicNFAMachineBuilder{} }
+
+func (s *symbolicNFAMachineBuilder) States(states []string) SymbolicNFAMachineBuilder {
+	s.states = states
+	return s
+}
+func (s *symbolicNFAMachineBuilder) StartStates(startStates []string) SymbolicNFAMachineBuilder {
+	s.startStates = startStates
+	return s
+}
+func (s *symbolicNFAMachineBuilder) AcceptStates(acceptStates []string) SymbolicNFAMachineBuilder {
+	s.acceptStates = acceptStates
+	return s
+}
+func (s *symbolicNFAMachineBuilder) Transitions(transitions map[string][]SymbolicEdge) SymbolicNFAMachineBuilder {
+	s.transitions = transitions
+	return s
+}
+func (s symbolicNFAMachineBuilder) Build() (SymbolicNFAMachine, error) {
+	states := set.NewStringSet(s.states...)
+	if !states.In(s.startStates...) {
+		return nil, ErrInvalidStartStates
+	}
+	if !states.In(s.acceptStates...) {
+		return nil, ErrInvalidAcceptStates
+	}
+	transitions := make(map[string][]SymbolicEdge, len(s.transitions))
+	for state, edges := range s.transitions {
+		if !states.In(state) {
+			return nil, ErrInvalidTransitions
+		}
+		for _, e := range edges {
+			if !states.In(e.To) {
+				return nil, ErrInvalidTransitions
+			}
+			if e.Class.IsEmpty() {
+				continue
+			}
+			transitions[state] = append(transitions[state], e)
+		}
+	}
+	return &symbolicNFAMachine{
+		states:        states,
+		startStates:   set.NewStringSet(s.startStates...),
+		acceptStates:  set.NewStringSet(s.acceptStates...),
+		transitions:   transitions,
+		currentStates: set.NewStringSet(s.startStates...),
+	}, nil
+}
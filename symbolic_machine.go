@@ -0,0 +1,272 @@
+package roughfa
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/berquerant/roughfa/internal/dot"
+	"github.com/berquerant/roughfa/internal/set"
+)
+
+type (
+	// SymbolicEdge is a transition by any rune in Class to the state To.
+	SymbolicEdge struct {
+		Class RuneClass
+		To    string
+	}
+
+	// SymbolicNFAMachine is a runner of the non deterministic finite automaton
+	// whose transitions are labeled by the sets of runes instead of the runes.
+	// The input characters are all runes, and a symbolic machine has no epsilon transitions.
+	SymbolicNFAMachine interface {
+		// States returns the current states.
+		States() []string
+		// Put inputs a character.
+		// Returns an error if no transitions.
+		Put(x rune) error
+		// IsAccepted returns true if the current states are acceptable.
+		IsAccepted() bool
+		// Reset resets the current states to the start state.
+		Reset()
+		// Accepts returns true if this accepts the input.
+		// Accepts runs from the start without changing the current states.
+		Accepts(input string) bool
+		// Transitions returns the edges from each state.
+		Transitions() map[string][]SymbolicEdge
+		// IsDFA returns true if this has a single start state and the edges from each state are disjoint.
+		IsDFA() bool
+		// Determinize creates a symbolic dfa by the powerset construction.
+		// The overlapping classes of the edges from a set of states are split into the disjoint classes, minterms,
+		// so the edges from each state of the dfa are disjoint.
+		// The states are named by sequential numbers.
+		Determinize() SymbolicNFAMachine
+		// ToDot generates Dot whose edges are labeled like [a-z].
+		ToDot() (dot.Dot, error)
+	}
+
+	symbolicNFAMachine struct {
+		states        set.StringSet
+		startStates   set.StringSet
+		acceptStates  set.StringSet
+		transitions   map[string][]SymbolicEdge
+		currentStates set.StringSet
+	}
+
+	// minterm is a class contained by the classes specified by members and disjoint from the others.
+	minterm struct {
+		class   RuneClass
+		members []int
+	}
+)
+
+func (s symbolicNFAMachine) States() []string { return s.currentStates.Unwrap() }
+func (s *symbolicNFAMachine) Reset()          { s.currentStates = s.startStates.Clone() }
+func (s symbolicNFAMachine) IsAccepted() bool {
+	return s.currentStates.And(s.acceptStates).Len() > 0
+}
+func (s *symbolicNFAMachine) Put(x rune) error {
+	if s.currentStates.Len() == 0 {
+		return ErrEmptyStates
+	}
+	s.currentStates = s.next(s.currentStates, x)
+	if s.currentStates.Len() == 0 {
+		return ErrEmptyStates
+	}
+	return nil
+}
+
+func (s symbolicNFAMachine) next(states set.StringSet, x rune) set.StringSet {
+	next := set.NewStringSet()
+	for _, state := range states.Unwrap() {
+		for _, e := range s.transitions[state] {
+			if e.Class.Contains(x) {
+				next.Add(e.To)
+			}
+		}
+	}
+	return next
+}
+
+func (s symbolicNFAMachine) Accepts(input string) bool {
+	states := s.startStates.Clone()
+	for _, c := range input {
+		if states = s.next(states, c); states.Len() == 0 {
+			return false
+		}
+	}
+	return states.And(s.acceptStates).Len() > 0
+}
+
+func (s symbolicNFAMachine) Transitions() map[string][]SymbolicEdge {
+	t := make(map[string][]SymbolicEdge, len(s.transitions))
+	for k, v := range s.transitions {
+		t[k] = append([]SymbolicEdge{}, v...)
+	}
+	return t
+}
+
+func (s symbolicNFAMachine) IsDFA() bool {
+	if s.startStates.Len() != 1 {
+		return false
+	}
+	for _, edges := range s.transitions {
+		for i, x := range edges {
+			for _, y := range edges[i+1:] {
+				if !x.Class.Intersect(y.Class).IsEmpty() {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func (s symbolicNFAMachine) Determinize() SymbolicNFAMachine {
+	var (
+		namer        = newPowersetNamer(NumberPowersetNaming)
+		dfaStatesMap = map[string]string{}
+		states       = set.NewStringSet()
+		acceptStates = set.NewStringSet()
+		transitions  = map[string][]SymbolicEdge{}
+		nameOf       = func(x set.StringSet) (string, bool) {
+			k := powersetKey(x)
+			if name, ok := dfaStatesMap[k]; ok {
+				return name, false
+			}
+			name := namer.name(sortedStrings(x))
+			dfaStatesMap[k] = name
+			return name, true
+		}
+		start, _ = nameOf(s.startStates)
+		q        = []set.StringSet{s.startStates.Clone()}
+	)
+	for len(q) > 0 {
+		dState := q[0]
+		q = q[1:]
+		from, _ := nameOf(dState)
+		states.Add(from)
+		if dState.And(s.acceptStates).Len() > 0 {
+			acceptStates.Add(from)
+		}
+		var edges []SymbolicEdge
+		for _, x := range sortedStrings(dState) {
+			edges = append(edges, s.transitions[x]...)
+		}
+		classes := make([]RuneClass, len(edges))
+		for i, e := range edges {
+			classes[i] = e.Class
+		}
+		for _, m := range minterms(classes) {
+			dNext := set.NewStringSet()
+			for _, i := range m.members {
+				dNext.Add(edges[i].To)
+			}
+			to, isNew := nameOf(dNext)
+			if isNew {
+				q = append(q, dNext)
+			}
+			transitions[from] = append(transitions[from], SymbolicEdge{
+				Class: m.class,
+				To:    to,
+			})
+		}
+	}
+	return &symbolicNFAMachine{
+		states:        states,
+		startStates:   set.NewStringSet(start),
+		acceptStates:  acceptStates,
+		transitions:   mergeSymbolicEdges(transitions),
+		currentStates: set.NewStringSet(start),
+	}
+}
+
+func (s symbolicNFAMachine) ToDot() (dot.Dot, error) {
+	t := make(map[string]map[string][]string, len(s.transitions))
+	for from, edges := range mergeSymbolicEdges(s.transitions) {
+		t[from] = make(map[string][]string, len(edges))
+		for _, e := range edges {
+			label := e.Class.String()
+			t[from][label] = append(t[from][label], e.To)
+		}
+	}
+	return dot.NewSymbolicDotBuilder().
+		StartStates(s.startStates.Unwrap()).
+		States(s.states.Unwrap()).
+		AcceptStates(s.acceptStates.Unwrap()).
+		Transitions(t).
+		Build()
+}
+
+// mergeSymbolicEdges merges the edges to the same state into an edge.
+func mergeSymbolicEdges(transitions map[string][]SymbolicEdge) map[string][]SymbolicEdge {
+	t := make(map[string][]SymbolicEdge, len(transitions))
+	for from, edges := range transitions {
+		var (
+			classes = map[string]RuneClass{}
+			tos     []string
+		)
+		for _, e := range edges {
+			c, ok := classes[e.To]
+			if !ok {
+				tos = append(tos, e.To)
+			}
+			classes[e.To] = c.Union(e.Class)
+		}
+		sort.Strings(tos)
+		for _, to := range tos {
+			t[from] = append(t[from], SymbolicEdge{
+				Class: classes[to],
+				To:    to,
+			})
+		}
+	}
+	return t
+}
+
+// minterms splits the union of the classes into the disjoint classes
+// such that each of them is contained by or disjoint from each of the classes.
+// The minterms are sorted by the least rune.
+func minterms(classes []RuneClass) []*minterm {
+	var bounds []rune
+	for _, c := range classes {
+		for _, x := range c.ranges {
+			bounds = append(bounds, x.Lo, x.Hi+1)
+		}
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+	var (
+		groups = map[string]*minterm{}
+		result []*minterm
+	)
+	for i := 0; i+1 < len(bounds); i++ {
+		lo, hi := bounds[i], bounds[i+1]-1
+		if lo > hi {
+			// duplicated bound
+			continue
+		}
+		var members []int
+		for j, c := range classes {
+			if c.Contains(lo) {
+				members = append(members, j)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		var (
+			key = fmt.Sprint(members)
+			r   = RuneRange{Lo: lo, Hi: hi}
+		)
+		if m, ok := groups[key]; ok {
+			m.class = m.class.Union(NewRuneClass(r))
+			continue
+		}
+		m := &minterm{
+			class:   NewRuneClass(r),
+			members: members,
+		}
+		groups[key] = m
+		result = append(result, m)
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package roughfa_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSymbolicNFAMachine(t *testing.T) {
+	var (
+		letter = roughfa.NewRuneClassFromTable(unicode.Letter)
+		digit  = roughfa.NewRuneClass(roughfa.RuneRange{Lo: '0', Hi: '9'})
+		lower  = roughfa.NewRuneClass(roughfa.RuneRange{Lo: 'a', Hi: 'z'})
+	)
+	// accepts the identifiers and the lower words followed by !
+	m, err := roughfa.NewSymbolicNFAMachineBuilder().
+		States([]string{"s", "id", "w", "bang"}).
+		StartStates([]string{"s"}).
+		AcceptStates([]string{"id", "bang"}).
+		Transitions(map[string][]roughfa.SymbolicEdge{
+			"s": {
+				{Class: letter, To: "id"},
+				{Class: lower, To: "w"},
+			},
+			"id": {
+				{Class: letter.Union(digit), To: "id"},
+			},
+			"w": {
+				{Class: lower, To: "w"},
+				{Class: roughfa.NewRuneClassOf('!'), To: "bang"},
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.False(t, m.IsDFA())
+	d := m.Determinize()
+	assert.True(t, d.IsDFA())
+	for _, tc := range []struct {
+		input string
+		want  bool
+	}{
+		{input: "", want: false},
+		{input: "x", want: true},
+		{input: "x1", want: true},
+		{input: "ひらがな2", want: true},
+		{input: "abc!", want: true},
+		{input: "aBc!", want: false},
+		{input: "1x", want: false},
+		{input: "abc!!", want: false},
+	} {
+		assert.Equal(t, tc.want, m.Accepts(tc.input), "nfa %q", tc.input)
+		assert.Equal(t, tc.want, d.Accepts(tc.input), "dfa %q", tc.input)
+	}
+
+	// {s} has the edges by the letters except lower, the lower letters, ...
+	var start []roughfa.SymbolicEdge
+	for _, es := range d.Transitions() {
+		if len(es) == 2 {
+			start = es
+		}
+	}
+	if assert.Equal(t, 2, len(start)) {
+		classes := []string{start[0].Class.String(), start[1].Class.String()}
+		assert.Contains(t, classes, "[a-z]")
+	}
+
+	g, err := m.ToDot()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, strings.Contains(g.AsDot(), `label="[a-z]"`), g.AsDot())
+
+	m.Reset()
+	assert.Nil(t, m.Put('a'))
+	assert.ElementsMatch(t, []string{"id", "w"}, m.States())
+	assert.Equal(t, roughfa.ErrEmptyStates, m.Put('?'))
+}
@@ -0,0 +1,111 @@
+package roughfa
+
+import "github.com/berquerant/roughfa/internal/set"
+
+type (
+	// Trace is a record of a run of a machine.
+	Trace struct {
+		// Start is the start states.
+		Start []string
+		// StartClosure is the states added to the start states by epsilon transitions.
+		StartClosure []string
+		// Steps are the steps in order of the input.
+		Steps []*TraceStep
+		// Accepted is true if the machine accepted the input.
+		Accepted bool
+	}
+
+	// TraceStep is a record of reading a character.
+	// The states are sorted.
+	TraceStep struct {
+		// Char is the read character.
+		Char rune
+		// Before is the states before reading Char.
+		Before []string
+		// Moved is the states reached by the transitions by Char.
+		Moved []string
+		// Closure is the states added to Moved by epsilon transitions.
+		Closure []string
+		// After is the states after reading Char, the union of Moved and Closure.
+		After []string
+	}
+)
+
+func (s dfaMachine) Accepts(input string) bool {
+	t, err := s.Run(input)
+	return err == nil && t.Accepted
+}
+
+func (s dfaMachine) Run(input string) (*Trace, error) {
+	var (
+		state = s.startState
+		trace = &Trace{
+			Start: []string{state},
+		}
+	)
+	for _, c := range input {
+		if s.chars.Len() > 0 && !s.chars.In(c) {
+			return trace, ErrInvalidInputChar
+		}
+		next, ok := s.move(state, c)
+		if !ok {
+			return trace, ErrOutOfTransition
+		}
+		trace.Steps = append(trace.Steps, &TraceStep{
+			Char:   c,
+			Before: []string{state},
+			Moved:  []string{next},
+			After:  []string{next},
+		})
+		state = next
+	}
+	trace.Accepted = s.acceptStates.In(state)
+	return trace, nil
+}
+
+func (s nfaMachine) Accepts(input string) bool {
+	t, err := s.Run(input)
+	return err == nil && t.Accepted
+}
+
+func (s nfaMachine) Run(input string) (*Trace, error) {
+	var (
+		states = s.epsilonClosure(s.startStates)
+		trace  = &Trace{
+			Start:        sortedStrings(s.startStates),
+			StartClosure: sortedStrings(s.closureDiff(states, s.startStates)),
+		}
+	)
+	for _, c := range input {
+		if s.chars.Len() > 0 && !s.chars.In(c) {
+			return trace, ErrInvalidInputChar
+		}
+		moved := set.NewStringSet()
+		for _, x := range states.Unwrap() {
+			if toStates, ok := s.move(x, c); ok {
+				moved.Add(toStates.Unwrap()...)
+			}
+		}
+		next := s.epsilonClosure(moved)
+		trace.Steps = append(trace.Steps, &TraceStep{
+			Char:    c,
+			Before:  sortedStrings(states),
+			Moved:   sortedStrings(moved),
+			Closure: sortedStrings(s.closureDiff(next, moved)),
+			After:   sortedStrings(next),
+		})
+		if next.Len() == 0 {
+			return trace, ErrEmptyStates
+		}
+		states = next
+	}
+	trace.Accepted = states.And(s.acceptStates).Len() > 0
+	return trace, nil
+}
+
+// closureDiff returns the states in closure but not in states.
+func (nfaMachine) closureDiff(closure, states set.StringSet) set.StringSet {
+	x := closure.Clone()
+	x.Del(states.Unwrap()...)
+	return x
+}
@@ -0,0 +1,152 @@
+package roughfa_test
+
+import (
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+type nfaRunTestcase struct {
+	name  string
+	input string
+	want  *roughfa.Trace
+	err   error
+}
+
+func (s nfaRunTestcase) test(t *testing.T) {
+	// accepts a*b
+	m, err := roughfa.NewNFAMachineBuilder().
+		States([]string{"0", "1", "2", "3"}).
+		StartStates([]string{"0"}).
+		AcceptStates([]string{"3"}).
+		Transitions(map[string]map[rune][]string{
+			"0": {
+				roughfa.Epsilon: {"1"},
+			},
+			"1": {
+				'a': {"0"},
+				'b': {"2"},
+			},
+			"2": {
+				roughfa.Epsilon: {"3"},
+			},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	got, err := m.Run(s.input)
+	assert.Equal(t, s.err, err)
+	assert.Equal(t, s.want, got)
+	assert.Equal(t, err == nil && s.want.Accepted, m.Accepts(s.input))
+	assert.Equal(t, []string{"0"}, m.States(), "current states")
+}
+
+func TestNFARun(t *testing.T) {
+	for _, tc := range []*nfaRunTestcase{
+		{
+			name: "empty",
+			want: &roughfa.Trace{
+				Start:        []string{"0"},
+				StartClosure: []string{"1"},
+			},
+		},
+		{
+			name:  "accepted",
+			input: "ab",
+			want: &roughfa.Trace{
+				Start:        []string{"0"},
+				StartClosure: []string{"1"},
+				Steps: []*roughfa.TraceStep{
+					{
+						Char:    'a',
+						Before:  []string{"0", "1"},
+						Moved:   []string{"0"},
+						Closure: []string{"1"},
+						After:   []string{"0", "1"},
+					},
+					{
+						Char:    'b',
+						Before:  []string{"0", "1"},
+						Moved:   []string{"2"},
+						Closure: []string{"3"},
+						After:   []string{"2", "3"},
+					},
+				},
+				Accepted: true,
+			},
+		},
+		{
+			name:  "no transitions",
+			input: "bb",
+			want: &roughfa.Trace{
+				Start:        []string{"0"},
+				StartClosure: []string{"1"},
+				Steps: []*roughfa.TraceStep{
+					{
+						Char:    'b',
+						Before:  []string{"0", "1"},
+						Moved:   []string{"2"},
+						Closure: []string{"3"},
+						After:   []string{"2", "3"},
+					},
+					{
+						Char:    'b',
+						Before:  []string{"2", "3"},
+						Moved:   []string{},
+						Closure: []string{},
+						After:   []string{},
+					},
+				},
+			},
+			err: roughfa.ErrEmptyStates,
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestDFARun(t *testing.T) {
+	m, err := roughfa.NewDFAMachineBuilder().
+		States([]string{"even", "odd"}).
+		Chars([]rune{'0', '1'}).
+		StartState("even").
+		AcceptStates([]string{"odd"}).
+		Transitions(map[string]map[rune]string{
+			"even": {'1': "odd"},
+			"odd":  {'1': "even", '0': "odd"},
+		}).
+		Build()
+	if !assert.Nil(t, err) {
+		return
+	}
+	got, err := m.Run("10")
+	assert.Nil(t, err)
+	assert.Equal(t, &roughfa.Trace{
+		Start: []string{"even"},
+		Steps: []*roughfa.TraceStep{
+			{
+				Char:   '1',
+				Before: []string{"even"},
+				Moved:  []string{"odd"},
+				After:  []string{"odd"},
+			},
+			{
+				Char:   '0',
+				Before: []string{"odd"},
+				Moved:  []string{"odd"},
+				After:  []string{"odd"},
+			},
+		},
+		Accepted: true,
+	}, got)
+	assert.True(t, m.Accepts("10"))
+	assert.False(t, m.Accepts("11"))
+	assert.False(t, m.Accepts("0"))
+	_, err = m.Run("0")
+	assert.Equal(t, roughfa.ErrOutOfTransition, err)
+	_, err = m.Run("12")
+	assert.Equal(t, roughfa.ErrInvalidInputChar, err)
+	assert.Equal(t, "even", m.State(), "current state")
+}
@@ -0,0 +1,29 @@
+package roughfa
+
+import (
+	"sort"
+
+	"github.com/berquerant/roughfa/internal/set"
+)
+
+// sortedRunes returns the elements of x in ascending order.
+func sortedRunes(x set.RuneSet) []rune {
+	v := x.Unwrap()
+	sort.Slice(v, func(i, j int) bool { return v[i] < v[j] })
+	return v
+}
+
+// sortedStrings returns the elements of x in ascending order.
+func sortedStrings(x set.StringSet) []string {
+	v := x.Unwrap()
+	sort.Strings(v)
+	return v
+}
+
+// uniqueName returns name, or name followed by primes if name is used.
+func uniqueName(used set.StringSet, name string) string {
+	for used.In(name) {
+		name += "'"
+	}
+	return name
+}
@@ -0,0 +1,155 @@
+package roughfa
+
+type (
+	// WordIterator iterates the words accepted by a machine in shortlex order,
+	// in order of the length, and in order of the runes for the words of the same length.
+	WordIterator interface {
+		// Next returns the next word.
+		// Returns false if no more words.
+		Next() (string, bool)
+	}
+
+	wordIterator struct {
+		// m is a dfa that consists of the useful states.
+		m        *nfaMachine
+		alphabet []rune
+		// maxLen is the max length of the words, negative means unbounded
+		maxLen int
+		// limit is the max number of the words, negative means unlimited
+		limit int
+		count int
+		// length is the length of the words of the current stack
+		length int
+		stack  []*wordFrame
+		word   []rune
+		// layers[k] is the states from which an accept state is reachable by exactly k characters
+		layers []map[string]bool
+	}
+
+	// wordFrame is a state on the path of the word and the index of the next character to try.
+	wordFrame struct {
+		state string
+		i     int
+	}
+)
+
+// NewWordIterator creates a new WordIterator of the words accepted by m
+// whose lengths are not greater than maxLen and the number of them is not greater than limit.
+// maxLen and limit are unbounded if they are negative.
+// If m accepts infinitely many words, the iteration does not end unless maxLen or limit is bounded.
+func NewWordIterator(m NFAMachine, maxLen, limit int) WordIterator {
+	var (
+		d      = asNFAMachine(m).determinize()
+		useful = d.reachableStates().And(d.coReachableStates())
+		u      = d.restrict(useful)
+	)
+	if _, _, ok := u.findCycle(); !ok && (maxLen < 0 || maxLen >= u.states.Len()) {
+		// the longest word of the acyclic dfa is shorter than the number of the states
+		maxLen = u.states.Len() - 1
+	}
+	return &wordIterator{
+		m:        u,
+		alphabet: sortedRunes(u.alphabet()),
+		maxLen:   maxLen,
+		limit:    limit,
+		length:   -1,
+	}
+}
+
+// Words returns the words accepted by m in shortlex order.
+// See NewWordIterator for maxLen and limit.
+// Returns ErrInfiniteLanguage if m accepts infinitely many words and both maxLen and limit are unbounded.
+func Words(m NFAMachine, maxLen, limit int) ([]string, error) {
+	if maxLen < 0 && limit < 0 {
+		if ok, _ := m.IsFinite(); !ok {
+			return nil, ErrInfiniteLanguage
+		}
+	}
+	var (
+		it    = NewWordIterator(m, maxLen, limit)
+		words []string
+	)
+	for {
+		w, ok := it.Next()
+		if !ok {
+			return words, nil
+		}
+		words = append(words, w)
+	}
+}
+
+func (s *wordIterator) Next() (string, bool) {
+	if s.limit >= 0 && s.count >= s.limit || s.m.startStates.Len() == 0 {
+		return "", false
+	}
+	for {
+		if len(s.stack) == 0 {
+			s.length++
+			if s.maxLen >= 0 && s.length > s.maxLen {
+				return "", false
+			}
+			start := s.m.startStates.Unwrap()[0]
+			if s.canAccept(start, s.length) {
+				s.stack = append(s.stack, &wordFrame{state: start})
+			}
+			continue
+		}
+		var (
+			top   = s.stack[len(s.stack)-1]
+			depth = len(s.stack) - 1
+		)
+		if depth == s.length {
+			w := string(s.word)
+			s.pop()
+			s.count++
+			return w, true
+		}
+		pushed := false
+		for top.i < len(s.alphabet) {
+			c := s.alphabet[top.i]
+			top.i++
+			next, ok := s.m.dfaNext(top.state, c)
+			if !ok || !s.canAccept(next, s.length-depth-1) {
+				continue
+			}
+			s.stack = append(s.stack, &wordFrame{state: next})
+			s.word = append(s.word, c)
+			pushed = true
+			break
+		}
+		if !pushed {
+			s.pop()
+		}
+	}
+}
+
+func (s *wordIterator) pop() {
+	s.stack = s.stack[:len(s.stack)-1]
+	if len(s.word) > 0 {
+		s.word = s.word[:len(s.word)-1]
+	}
+}
+
+// canAccept returns true if an accept state is reachable from the state by exactly n characters.
+func (s *wordIterator) canAccept(state string, n int) bool {
+	for len(s.layers) <= n {
+		layer := map[string]bool{}
+		if len(s.layers) == 0 {
+			for _, x := range s.m.acceptStates.Unwrap() {
+				layer[x] = true
+			}
+		} else {
+			prev := s.layers[len(s.layers)-1]
+			for from, x := range s.m.transitions {
+				for _, toStates := range x {
+					if prev[toStates.Unwrap()[0]] {
+						layer[from] = true
+						break
+					}
+				}
+			}
+		}
+		s.layers = append(s.layers, layer)
+	}
+	return s.layers[n][state]
+}
@@ -0,0 +1,112 @@
+package roughfa_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/berquerant/roughfa"
+	"github.com/stretchr/testify/assert"
+)
+
+type wordsTestcase struct {
+	name    string
+	pattern string
+	chars   string
+	maxLen  int
+	limit   int
+	want    []string
+	err     error
+}
+
+func (s wordsTestcase) test(t *testing.T) {
+	m, err := roughfa.CompileRegexp(s.pattern)
+	if !assert.Nil(t, err) {
+		return
+	}
+	got, err := roughfa.Words(m, s.maxLen, s.limit)
+	assert.Equal(t, s.err, err)
+	if s.want != nil {
+		assert.Equal(t, s.want, got)
+		return
+	}
+	if err != nil {
+		return
+	}
+	// allWords generates the words in shortlex order if the chars are sorted
+	r := regexp.MustCompile(`^(?:` + s.pattern + `)$`)
+	var want []string
+	for _, w := range allWords(s.chars, s.maxLen) {
+		if r.MatchString(w) && (s.limit < 0 || len(want) < s.limit) {
+			want = append(want, w)
+		}
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestWords(t *testing.T) {
+	for _, tc := range []*wordsTestcase{
+		{
+			name:    "finite",
+			pattern: "(b|a)(c|)|d",
+			maxLen:  -1,
+			limit:   -1,
+			want:    []string{"a", "b", "d", "ac", "bc"},
+		},
+		{
+			name:    "empty word",
+			pattern: "",
+			maxLen:  -1,
+			limit:   -1,
+			want:    []string{""},
+		},
+		{
+			name:    "empty language",
+			pattern: "a|b",
+			maxLen:  0,
+			limit:   -1,
+		},
+		{
+			name:    "infinite",
+			pattern: "(ab|b)*a?",
+			maxLen:  -1,
+			limit:   -1,
+			err:     roughfa.ErrInfiniteLanguage,
+		},
+		{
+			name:    "max length",
+			pattern: "(ab|b)*a?",
+			chars:   "ab",
+			maxLen:  6,
+			limit:   -1,
+		},
+		{
+			name:    "limit",
+			pattern: "(aa|bbb)*",
+			chars:   "ab",
+			maxLen:  8,
+			limit:   5,
+		},
+		{
+			name:    "gap",
+			pattern: "(aaaa)*",
+			maxLen:  -1,
+			limit:   3,
+			want:    []string{"", "aaaa", "aaaaaaaa"},
+		},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestWordIterator(t *testing.T) {
+	m, err := roughfa.CompileRegexp("a*b")
+	if !assert.Nil(t, err) {
+		return
+	}
+	it := roughfa.NewWordIterator(m, -1, -1)
+	for _, want := range []string{"b", "ab", "aab", "aaab"} {
+		got, ok := it.Next()
+		assert.True(t, ok)
+		assert.Equal(t, want, got)
+	}
+}